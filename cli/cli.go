@@ -39,10 +39,40 @@ func HandleDefaultSections(app *kingpin.Application) {
 	resourcesQueries := queries.NewResources()
 	resourceUnreserveQueries := queries.NewUnreserveResources()
 	resourceListQueries := queries.NewResourceList()
+	resourceSummaryQueries := queries.NewResourceSummary()
+	resourceSnapshotQueries := queries.NewResourceSnapshot()
+	resourceRestoreQueries := queries.NewRestoreResources()
+	resourceCloneQueries := queries.NewCloneResources()
+	resourceMoveQueries := queries.NewMoveResources()
+	migrateRoleQueries := queries.NewMigrateRole()
+	roleQuotaQueries := queries.NewRoleQuota()
+	roleWeightsQueries := queries.NewRoleWeights()
+	roleListQueries := queries.NewRoleList()
+	maintenanceQueries := queries.NewMaintenance()
+	agentDrainQueries := queries.NewAgentDrain()
+	agentListQueries := queries.NewAgentList()
+	capacityPlannerQueries := queries.NewCapacityPlanner()
+	spreadReportQueries := queries.NewSpreadReport()
+	fragmentationReportQueries := queries.NewFragmentationReport()
 
 	commands.HandleReserveResourcesSection(app, resourcesQueries)
 	commands.HandleUnreserveResourcesSection(app, resourceUnreserveQueries)
 	commands.HandleListResourcesSection(app, resourceListQueries)
+	commands.HandleSummarySection(app, resourceSummaryQueries)
+	commands.HandleSnapshotSection(app, resourceSnapshotQueries)
+	commands.HandleRestoreSection(app, resourceRestoreQueries)
+	commands.HandleCloneSection(app, resourceCloneQueries)
+	commands.HandleMoveSection(app, resourceMoveQueries)
+	commands.HandleMigrateRoleSection(app, migrateRoleQueries)
+	commands.HandleQuotaSection(app, roleQuotaQueries)
+	commands.HandleWeightsSection(app, roleWeightsQueries)
+	commands.HandleRolesSection(app, roleListQueries)
+	commands.HandleMaintenanceSection(app, maintenanceQueries)
+	commands.HandleAgentSection(app, agentDrainQueries)
+	commands.HandleAgentsSection(app, agentListQueries)
+	commands.HandleFindCapacitySection(app, capacityPlannerQueries)
+	commands.HandleSpreadSection(app, spreadReportQueries)
+	commands.HandleFragmentationSection(app, fragmentationReportQueries)
 }
 
 // New instantiates a new kingpin.Application and returns a reference to it.
@@ -75,6 +105,9 @@ func New() *kingpin.Application {
 	// Enable verbose logging with '-v', in addition to DCOS_DEBUG/DCOS_LOG_LEVEL which are handled above.
 	app.Flag("verbose", "Enable extra logging of requests/responses").Short('v').BoolVar(&config.Verbose)
 
+	// Unit of memory and disk in output. Amounts are always given to Mesos in MB.
+	app.Flag("units", "Unit of memory and disk in output: auto, MB or GiB").Default("MB").EnumVar(&queries.OutputUnits, "auto", "MB", "GiB")
+
 	// --info and --config-schema are required by the main DC/OS CLI:
 	// Prints a description of the module.
 	app.Flag("info", "Show short description.").Hidden().PreAction(func(*kingpin.Application, *kingpin.ParseElement, *kingpin.ParseContext) error {
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"errors"
+	"github.com/minyk/dcos-resources/queries"
+	"gopkg.in/alecthomas/kingpin.v3-unstable"
+	"time"
+)
+
+type agentHandler struct {
+	q              *queries.AgentDrain
+	agentID        string
+	maxGracePeriod time.Duration
+	markGone       bool
+	unreserve      bool
+	timeout        time.Duration
+	wait           time.Duration
+}
+
+func (cmd *agentHandler) handleDrain(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	if cmd.markGone && cmd.unreserve {
+		return errors.New("--mark-gone can not be used with --unreserve, the master rejects operations on gone agents")
+	}
+	return cmd.q.Drain(cmd.agentID, cmd.maxGracePeriod, cmd.markGone, cmd.unreserve, cmd.timeout, cmd.wait)
+}
+
+func (cmd *agentHandler) handleDeactivate(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.Deactivate(cmd.agentID)
+}
+
+func (cmd *agentHandler) handleReactivate(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.Reactivate(cmd.agentID)
+}
+
+// HandleAgentSection
+func HandleAgentSection(app *kingpin.Application, q *queries.AgentDrain) {
+	agent := app.Command("agent", "Drain, deactivate or reactivate an agent. Requires Mesos 1.9 or later.")
+	HandleAgentDrainCommand(agent.Command("drain", "Drain the tasks of an agent"), q)
+	HandleAgentDeactivateCommand(agent.Command("deactivate", "Stop offers of an agent"), q)
+	HandleAgentReactivateCommand(agent.Command("reactivate", "Resume offers of a drained or deactivated agent"), q)
+}
+
+func HandleAgentDrainCommand(drain *kingpin.CmdClause, q *queries.AgentDrain) {
+	cmd := &agentHandler{q: q}
+	drain.Action(cmd.handleDrain)
+	drain.Flag("agent-id", "Agent ID, unique ID prefix, hostname or IP of the agent to drain").Required().StringVar(&cmd.agentID)
+	drain.Flag("max-grace-period", "Upper bound of the kill grace period of tasks").Default("0s").DurationVar(&cmd.maxGracePeriod)
+	drain.Flag("mark-gone", "Remove the agent from the cluster once it is drained").BoolVar(&cmd.markGone)
+	drain.Flag("unreserve", "Unreserve the reservations of the agent once all executors have exited").BoolVar(&cmd.unreserve)
+	drain.Flag("timeout", "How long to wait for executors to exit with --unreserve").Default("10m").DurationVar(&cmd.timeout)
+	waitVar(drain, &cmd.wait)
+}
+
+func HandleAgentDeactivateCommand(deactivate *kingpin.CmdClause, q *queries.AgentDrain) {
+	cmd := &agentHandler{q: q}
+	deactivate.Action(cmd.handleDeactivate)
+	deactivate.Flag("agent-id", "Agent ID, unique ID prefix, hostname or IP of the agent to deactivate").Required().StringVar(&cmd.agentID)
+}
+
+func HandleAgentReactivateCommand(reactivate *kingpin.CmdClause, q *queries.AgentDrain) {
+	cmd := &agentHandler{q: q}
+	reactivate.Action(cmd.handleReactivate)
+	reactivate.Flag("agent-id", "Agent ID, unique ID prefix, hostname or IP of the agent to reactivate").Required().StringVar(&cmd.agentID)
+}
@@ -0,0 +1,24 @@
+package commands
+
+import (
+	"github.com/minyk/dcos-resources/queries"
+	"gopkg.in/alecthomas/kingpin.v3-unstable"
+)
+
+type agentsHandler struct {
+	q *queries.AgentList
+}
+
+func (cmd *agentsHandler) handleAgents(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.ListAgents()
+}
+
+// HandleAgentsSection
+func HandleAgentsSection(app *kingpin.Application, q *queries.AgentList) {
+	HandleAgentsCommands(app.Command("agents", "List agents with their resources"), q)
+}
+
+func HandleAgentsCommands(agents *kingpin.CmdClause, q *queries.AgentList) {
+	cmd := &agentsHandler{q: q}
+	agents.Action(cmd.handleAgents)
+}
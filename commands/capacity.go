@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"github.com/minyk/dcos-resources/queries"
+	"gopkg.in/alecthomas/kingpin.v3-unstable"
+	"time"
+)
+
+type findCapacityHandler struct {
+	q            *queries.CapacityPlanner
+	cpus         float64
+	mem          float64
+	disk         float64
+	count        int
+	spread       string
+	role         string
+	principal    string
+	enforceQuota bool
+	wait         time.Duration
+}
+
+func (cmd *findCapacityHandler) handleFindCapacity(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.FindCapacity(cmd.cpus, cmd.mem, cmd.disk, cmd.count, cmd.spread, cmd.role, cmd.principal, cmd.enforceQuota, cmd.wait)
+}
+
+// HandleFindCapacitySection
+func HandleFindCapacitySection(app *kingpin.Application, q *queries.CapacityPlanner) {
+	HandleFindCapacityCommands(app.Command("find-capacity", "Find agents with the unreserved capacity for a reservation"), q)
+}
+
+func HandleFindCapacityCommands(find *kingpin.CmdClause, q *queries.CapacityPlanner) {
+	cmd := &findCapacityHandler{q: q}
+	find.Action(cmd.handleFindCapacity)
+	cpusVar(find.Flag("cpus", "Amount of cpus needed on each agent, e.g. 0.5 or 500m").Default("0"), &cmd.cpus)
+	megabytesVar(find.Flag("mem", "Amount of memory needed on each agent, e.g. 512MiB or 4G. Plain numbers are MB.").Default("0"), &cmd.mem)
+	megabytesVar(find.Flag("disk", "Amount of disk needed on each agent, e.g. 512MiB or 4G. Plain numbers are MB.").Default("0"), &cmd.disk)
+	find.Flag("count", "Number of agents to find").Default("1").IntVar(&cmd.count)
+	find.Flag("spread", "Spread the agents across region, zone, hostname or an attribute, e.g. rack").Default("").StringVar(&cmd.spread)
+	find.Flag("reserve", "Reserve the resources for this role on the agents found").Default("").StringVar(&cmd.role)
+	find.Flag("principal", "Principal for reserve").Default("my-principal").StringVar(&cmd.principal)
+	find.Flag("enforce-quota", "Refuse reservations which exceed the quota of the role").BoolVar(&cmd.enforceQuota)
+	waitVar(find, &cmd.wait)
+}
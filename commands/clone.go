@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"github.com/minyk/dcos-resources/queries"
+	"gopkg.in/alecthomas/kingpin.v3-unstable"
+	"strings"
+	"time"
+)
+
+type cloneHandler struct {
+	q          *queries.CloneResources
+	fromAgent  string
+	toAgents   string
+	role       string
+	regenerate bool
+	wait       time.Duration
+}
+
+func (cmd *cloneHandler) handleClone(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.Clone(cmd.fromAgent, strings.Split(cmd.toAgents, ","), cmd.role, cmd.regenerate, cmd.wait)
+}
+
+// HandleCloneSection
+func HandleCloneSection(app *kingpin.Application, q *queries.CloneResources) {
+	HandleCloneCommands(app.Command("clone", "Reserve the reservation layout of an agent on other agents"), q)
+}
+
+func HandleCloneCommands(clone *kingpin.CmdClause, q *queries.CloneResources) {
+	cmd := &cloneHandler{q: q}
+	clone.Action(cmd.handleClone)
+	clone.Flag("from-agent", "Agent ID, unique ID prefix, hostname or IP of the agent to copy reservations from").Required().StringVar(&cmd.fromAgent)
+	clone.Flag("to-agent", "Comma separated agents to reserve on, given as IDs, unique ID prefixes, hostnames or IPs").Required().StringVar(&cmd.toAgents)
+	clone.Flag("role", "Only clone reservations of this role").Default("").StringVar(&cmd.role)
+	clone.Flag("regenerate-ids", "Generate new resource IDs and persistence IDs instead of removing them").BoolVar(&cmd.regenerate)
+	waitVar(clone, &cmd.wait)
+}
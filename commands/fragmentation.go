@@ -0,0 +1,24 @@
+package commands
+
+import (
+	"github.com/minyk/dcos-resources/queries"
+	"gopkg.in/alecthomas/kingpin.v3-unstable"
+)
+
+type fragmentationHandler struct {
+	q *queries.FragmentationReport
+}
+
+func (cmd *fragmentationHandler) handleFragmentation(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.Fragmentation()
+}
+
+// HandleFragmentationSection
+func HandleFragmentationSection(app *kingpin.Application, q *queries.FragmentationReport) {
+	HandleFragmentationCommands(app.Command("fragmentation", "Find stranded reservations and the largest task each role can launch"), q)
+}
+
+func HandleFragmentationCommands(fragmentation *kingpin.CmdClause, q *queries.FragmentationReport) {
+	cmd := &fragmentationHandler{q: q}
+	fragmentation.Action(cmd.handleFragmentation)
+}
@@ -6,9 +6,10 @@ import (
 )
 
 type resourceListHandler struct {
-	q       *queries.ResourceList
-	agentID string
-	role    string
+	q               *queries.ResourceList
+	agentID         string
+	role            string
+	includeChildren bool
 }
 
 // HandleScheduleSection
@@ -17,12 +18,13 @@ func HandleListResourcesSection(app *kingpin.Application, q *queries.ResourceLis
 }
 
 func (cmd *resourceListHandler) handleListResources(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
-	return cmd.q.ListResourcesFromNode(cmd.agentID, cmd.role)
+	return cmd.q.ListResourcesFromNode(cmd.agentID, cmd.role, cmd.includeChildren)
 }
 
 func HandleListResourcesCommands(resources *kingpin.CmdClause, q *queries.ResourceList) {
 	cmd := &resourceListHandler{q: q}
 	listResources := resources.Action(cmd.handleListResources)
-	listResources.Flag("agent-id", "Agent ID to list").Required().StringVar(&cmd.agentID)
+	listResources.Flag("agent-id", "Agent ID, unique ID prefix, hostname or IP of the agent to list").Required().StringVar(&cmd.agentID)
 	listResources.Flag("role", "Role for list").Required().StringVar(&cmd.role)
+	listResources.Flag("include-children", "Also list resources reserved for children of the role").BoolVar(&cmd.includeChildren)
 }
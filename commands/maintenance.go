@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"github.com/minyk/dcos-resources/queries"
+	"gopkg.in/alecthomas/kingpin.v3-unstable"
+	"time"
+)
+
+type maintenanceHandler struct {
+	q        *queries.Maintenance
+	machines []string
+	start    string
+	duration time.Duration
+	snapshot string
+}
+
+func (cmd *maintenanceHandler) handleSchedule(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.Schedule(cmd.machines, cmd.start, cmd.duration)
+}
+
+func (cmd *maintenanceHandler) handleStart(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.Start(cmd.machines, cmd.snapshot)
+}
+
+func (cmd *maintenanceHandler) handleStop(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.Stop(cmd.machines)
+}
+
+func (cmd *maintenanceHandler) handleStatus(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.Status()
+}
+
+// HandleMaintenanceSection
+func HandleMaintenanceSection(app *kingpin.Application, q *queries.Maintenance) {
+	maintenance := app.Command("maintenance", "Manage maintenance of machines")
+	HandleMaintenanceScheduleCommand(maintenance.Command("schedule", "Add a maintenance window for machines"), q)
+	HandleMaintenanceStartCommand(maintenance.Command("start", "Report reservations on machines and start maintenance"), q)
+	HandleMaintenanceStopCommand(maintenance.Command("stop", "Stop maintenance of machines"), q)
+	HandleMaintenanceStatusCommand(maintenance.Command("status", "Show maintenance windows and status of machines"), q)
+}
+
+func HandleMaintenanceScheduleCommand(schedule *kingpin.CmdClause, q *queries.Maintenance) {
+	cmd := &maintenanceHandler{q: q}
+	schedule.Action(cmd.handleSchedule)
+	schedule.Flag("machine", "Machine as hostname or hostname=ip. Can be repeated.").Required().StringsVar(&cmd.machines)
+	schedule.Flag("start", "Start of the window in RFC3339, e.g. 2019-10-01T09:00:00Z. Defaults to now.").Default("").StringVar(&cmd.start)
+	schedule.Flag("duration", "Duration of the window").Default("1h").DurationVar(&cmd.duration)
+}
+
+func HandleMaintenanceStartCommand(start *kingpin.CmdClause, q *queries.Maintenance) {
+	cmd := &maintenanceHandler{q: q}
+	start.Action(cmd.handleStart)
+	start.Flag("machine", "Machine as hostname or hostname=ip. Can be repeated.").Required().StringsVar(&cmd.machines)
+	start.Flag("snapshot", "Save reservations of the agents on the machines to this file").Default("").StringVar(&cmd.snapshot)
+}
+
+func HandleMaintenanceStopCommand(stop *kingpin.CmdClause, q *queries.Maintenance) {
+	cmd := &maintenanceHandler{q: q}
+	stop.Action(cmd.handleStop)
+	stop.Flag("machine", "Machine as hostname or hostname=ip. Can be repeated.").Required().StringsVar(&cmd.machines)
+}
+
+func HandleMaintenanceStatusCommand(status *kingpin.CmdClause, q *queries.Maintenance) {
+	cmd := &maintenanceHandler{q: q}
+	status.Action(cmd.handleStatus)
+}
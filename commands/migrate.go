@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"github.com/minyk/dcos-resources/queries"
+	"gopkg.in/alecthomas/kingpin.v3-unstable"
+	"time"
+)
+
+type migrateRoleHandler struct {
+	q       *queries.MigrateRole
+	from    string
+	to      string
+	agentID string
+	wait    time.Duration
+}
+
+func (cmd *migrateRoleHandler) handleMigrateRole(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.MigrateRole(cmd.from, cmd.to, cmd.agentID, cmd.wait)
+}
+
+// HandleMigrateRoleSection
+func HandleMigrateRoleSection(app *kingpin.Application, q *queries.MigrateRole) {
+	HandleMigrateRoleCommands(app.Command("migrate-role", "Migrate reservations from one role to another").Alias("migraterole"), q)
+}
+
+func HandleMigrateRoleCommands(migrate *kingpin.CmdClause, q *queries.MigrateRole) {
+	cmd := &migrateRoleHandler{q: q}
+	migrate.Action(cmd.handleMigrateRole)
+	migrate.Flag("from", "Role to migrate reservations from").Required().StringVar(&cmd.from)
+	migrate.Flag("to", "Role to migrate reservations to").Required().StringVar(&cmd.to)
+	migrate.Flag("agent-id", "Agent ID, unique ID prefix, hostname or IP of the agent to migrate. All agents are migrated if omitted.").Default("").StringVar(&cmd.agentID)
+	waitVar(migrate, &cmd.wait)
+}
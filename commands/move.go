@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"github.com/minyk/dcos-resources/queries"
+	"gopkg.in/alecthomas/kingpin.v3-unstable"
+	"time"
+)
+
+type moveHandler struct {
+	q         *queries.MoveResources
+	fromAgent string
+	toAgent   string
+	role      string
+	principal string
+	wait      time.Duration
+}
+
+func (cmd *moveHandler) handleMove(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.Move(cmd.fromAgent, cmd.toAgent, cmd.role, cmd.principal, cmd.wait)
+}
+
+// HandleMoveSection
+func HandleMoveSection(app *kingpin.Application, q *queries.MoveResources) {
+	HandleMoveCommands(app.Command("move", "Move unused reservations from one agent to another"), q)
+}
+
+func HandleMoveCommands(move *kingpin.CmdClause, q *queries.MoveResources) {
+	cmd := &moveHandler{q: q}
+	move.Action(cmd.handleMove)
+	move.Flag("from-agent", "Agent ID, unique ID prefix, hostname or IP of the agent to unreserve").Required().StringVar(&cmd.fromAgent)
+	move.Flag("to-agent", "Agent ID, unique ID prefix, hostname or IP of the agent to reserve").Required().StringVar(&cmd.toAgent)
+	move.Flag("role", "Role of the reservations to move").Required().StringVar(&cmd.role)
+	move.Flag("principal", "Only move reservations of this principal").Default("").StringVar(&cmd.principal)
+	waitVar(move, &cmd.wait)
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"errors"
+	"github.com/minyk/dcos-resources/queries"
+	"gopkg.in/alecthomas/kingpin.v3-unstable"
+)
+
+type quotaHandler struct {
+	q         *queries.RoleQuota
+	role      string
+	cpus      float64
+	mem       float64
+	disk      float64
+	limitCpus float64
+	limitMem  float64
+	limitDisk float64
+	force     bool
+	legacy    bool
+}
+
+func (cmd *quotaHandler) handleGet(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.GetQuota(cmd.role)
+}
+
+func (cmd *quotaHandler) handleSet(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	limits := scalars(cmd.limitCpus, cmd.limitMem, cmd.limitDisk)
+	if cmd.legacy && len(limits) > 0 {
+		return errors.New("--limit-cpus, --limit-mem and --limit-disk can not be used with --legacy, SET_QUOTA only sets guarantees")
+	}
+	return cmd.q.SetQuota(cmd.role, scalars(cmd.cpus, cmd.mem, cmd.disk), limits, cmd.force, cmd.legacy)
+}
+
+func (cmd *quotaHandler) handleRemove(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.RemoveQuota(cmd.role)
+}
+
+// scalars collects the non-zero amounts of cpus, mem and disk.
+func scalars(cpus float64, mem float64, disk float64) map[string]float64 {
+	values := make(map[string]float64)
+	if cpus > 0 {
+		values["cpus"] = cpus
+	}
+	if mem > 0 {
+		values["mem"] = mem
+	}
+	if disk > 0 {
+		values["disk"] = disk
+	}
+	return values
+}
+
+// HandleQuotaSection
+func HandleQuotaSection(app *kingpin.Application, q *queries.RoleQuota) {
+	quota := app.Command("quota", "Manage quota of roles")
+	HandleQuotaGetCommand(quota.Command("get", "Show quota and reserved resources of roles"), q)
+	HandleQuotaSetCommand(quota.Command("set", "Set quota of a role"), q)
+	HandleQuotaRemoveCommand(quota.Command("remove", "Remove quota of a role"), q)
+}
+
+func HandleQuotaGetCommand(get *kingpin.CmdClause, q *queries.RoleQuota) {
+	cmd := &quotaHandler{q: q}
+	get.Action(cmd.handleGet)
+	get.Flag("role", "Role to show. All roles are shown if omitted.").Default("").StringVar(&cmd.role)
+}
+
+func HandleQuotaSetCommand(set *kingpin.CmdClause, q *queries.RoleQuota) {
+	cmd := &quotaHandler{q: q}
+	set.Action(cmd.handleSet)
+	set.Flag("role", "Role to set quota").Required().StringVar(&cmd.role)
+	cpusVar(set.Flag("cpus", "Guaranteed amount of cpus, e.g. 0.5 or 500m").Default("0"), &cmd.cpus)
+	megabytesVar(set.Flag("mem", "Guaranteed amount of memory, e.g. 512MiB or 4G. Plain numbers are MB.").Default("0"), &cmd.mem)
+	megabytesVar(set.Flag("disk", "Guaranteed amount of disk, e.g. 512MiB or 4G. Plain numbers are MB.").Default("0"), &cmd.disk)
+	cpusVar(set.Flag("limit-cpus", "Limit of cpus, e.g. 0.5 or 500m").Default("0"), &cmd.limitCpus)
+	megabytesVar(set.Flag("limit-mem", "Limit of memory, e.g. 512MiB or 4G. Plain numbers are MB.").Default("0"), &cmd.limitMem)
+	megabytesVar(set.Flag("limit-disk", "Limit of disk, e.g. 512MiB or 4G. Plain numbers are MB.").Default("0"), &cmd.limitDisk)
+	set.Flag("force", "Skip the capacity validation of the master").BoolVar(&cmd.force)
+	set.Flag("legacy", "Use SET_QUOTA for masters older than Mesos 1.9. Limits are not supported.").BoolVar(&cmd.legacy)
+}
+
+func HandleQuotaRemoveCommand(remove *kingpin.CmdClause, q *queries.RoleQuota) {
+	cmd := &quotaHandler{q: q}
+	remove.Action(cmd.handleRemove)
+	remove.Flag("role", "Role to remove quota").Required().StringVar(&cmd.role)
+}
@@ -1,22 +1,58 @@
 package commands
 
 import (
+	"errors"
 	"github.com/minyk/dcos-resources/queries"
 	"gopkg.in/alecthomas/kingpin.v3-unstable"
+	"strings"
+	"time"
 )
 
 type reserveResourcesHandler struct {
-	q           *queries.ReserveResources
-	agentID     string
-	role        string
-	principal   string
-	frameworkID string
-	cpus        float64
-	mem         float64
+	q            *queries.ReserveResources
+	agentID      string
+	role         string
+	principal    string
+	frameworkID  string
+	cpus         queries.Amount
+	mem          queries.Amount
+	enforceQuota bool
+	selector     string
+	count        int
+	all          bool
+	resources    []string
+	bestEffort   bool
+	allRemaining bool
+	types        string
+	resourceID   string
+	idempotent   bool
+	wait         time.Duration
 }
 
 func (cmd *reserveResourcesHandler) handleReserve(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
-	return cmd.q.ReserveResource(cmd.agentID, cmd.role, cmd.principal, cmd.cpus, cmd.mem)
+	switch {
+	case cmd.selector == "" && cmd.agentID == "":
+		return errors.New("either --agent-id or --selector is required")
+	case cmd.selector != "" && cmd.agentID != "":
+		return errors.New("--agent-id and --selector can not be used together")
+	case cmd.selector != "" && cmd.bestEffort:
+		return errors.New("--best-effort can not be used with --selector")
+	case cmd.selector != "" && (cmd.count > 0) == cmd.all:
+		return errors.New("--selector requires either --count or --all")
+	case cmd.allRemaining && (cmd.selector != "" || cmd.cpus.IsSet() || cmd.mem.IsSet() || len(cmd.resources) > 0 || cmd.bestEffort):
+		return errors.New("--all-remaining can not be used with --selector, --cpus, --mem, --resource or --best-effort")
+	case (cmd.resourceID != "" || cmd.idempotent) && (cmd.selector != "" || cmd.allRemaining):
+		return errors.New("--resource-id and --idempotent can not be used with --selector or --all-remaining")
+	case cmd.allRemaining:
+		var types []string
+		if cmd.types != "" {
+			types = strings.Split(cmd.types, ",")
+		}
+		return cmd.q.ReserveAllRemaining(cmd.agentID, cmd.role, cmd.principal, types, cmd.enforceQuota, cmd.wait)
+	case cmd.selector != "":
+		return cmd.q.ReserveOnSelector(cmd.selector, cmd.count, cmd.all, cmd.role, cmd.principal, cmd.cpus, cmd.mem, cmd.resources, cmd.enforceQuota, cmd.wait)
+	}
+	return cmd.q.ReserveResource(cmd.agentID, cmd.role, cmd.principal, cmd.cpus, cmd.mem, cmd.resources, cmd.resourceID, cmd.idempotent, cmd.bestEffort, cmd.enforceQuota, cmd.wait)
 }
 
 // HandleScheduleSection
@@ -28,9 +64,20 @@ func HandleReserveResourcesSection(app *kingpin.Application, q *queries.ReserveR
 func HandleReserveResourcesCommands(resources *kingpin.CmdClause, q *queries.ReserveResources) {
 	cmd := &reserveResourcesHandler{q: q}
 	reserve := resources.Action(cmd.handleReserve)
-	reserve.Flag("agent-id", "Agent ID to reserve").Required().StringVar(&cmd.agentID)
+	reserve.Flag("agent-id", "Agent ID, unique ID prefix, hostname or IP of the agent to reserve").Default("").StringVar(&cmd.agentID)
 	reserve.Flag("role", "Role for reserve").Required().StringVar(&cmd.role)
 	reserve.Flag("principal", "Principal for reserve").Default("my-principal").StringVar(&cmd.principal)
-	reserve.Flag("cpus", "Amount of cpus to reserve").Default("0").Float64Var(&cmd.cpus)
-	reserve.Flag("mem", "Amount of memory to reserve. The unit is MB.").Default("0").Float64Var(&cmd.mem)
+	cpusAmountVar(reserve.Flag("cpus", "Amount of cpus to reserve, e.g. 0.5, 500m or 50% of the cpus of the agent").Default("0"), &cmd.cpus)
+	megabytesAmountVar(reserve.Flag("mem", "Amount of memory to reserve, e.g. 512MiB, 4G or 75% of the memory of the agent. Plain numbers are MB.").Default("0"), &cmd.mem)
+	reserve.Flag("resource", "Resource to reserve as name:type:value, e.g. gpus:scalar:1, ephemeral_ports:ranges:31000-31010 or network:set:a,b. Can be repeated.").StringsVar(&cmd.resources)
+	reserve.Flag("selector", "Reserve on agents matching attributes, region, zone or hostname, e.g. rack=r1,zone=us-east-1a").Default("").StringVar(&cmd.selector)
+	reserve.Flag("count", "Number of agents matching --selector to reserve on").Default("0").IntVar(&cmd.count)
+	reserve.Flag("all", "Reserve on all agents matching --selector").BoolVar(&cmd.all)
+	reserve.Flag("best-effort", "Reserve as much of the resources as is unreserved on the agent instead of refusing").BoolVar(&cmd.bestEffort)
+	reserve.Flag("all-remaining", "Reserve all unreserved resources of the agent, including each MOUNT disk and the port ranges").BoolVar(&cmd.allRemaining)
+	reserve.Flag("types", "Comma separated resources to reserve with --all-remaining, e.g. cpus,mem,disk. All if omitted.").Default("").StringVar(&cmd.types)
+	reserve.Flag("resource-id", "Label the reservation with this resource_id and reserve only what is not reserved with it yet").Default("").StringVar(&cmd.resourceID)
+	reserve.Flag("idempotent", "Reserve only what is not reserved for the role and principal on the agent yet").BoolVar(&cmd.idempotent)
+	reserve.Flag("enforce-quota", "Refuse reservations which exceed the quota of the role").BoolVar(&cmd.enforceQuota)
+	waitVar(reserve, &cmd.wait)
 }
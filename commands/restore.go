@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"github.com/minyk/dcos-resources/queries"
+	"gopkg.in/alecthomas/kingpin.v3-unstable"
+	"time"
+)
+
+type restoreHandler struct {
+	q        *queries.RestoreResources
+	file     string
+	agentID  string
+	agentMap map[string]string
+	wait     time.Duration
+}
+
+func (cmd *restoreHandler) handleRestore(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.Restore(cmd.file, cmd.agentID, cmd.agentMap, cmd.wait)
+}
+
+// HandleRestoreSection
+func HandleRestoreSection(app *kingpin.Application, q *queries.RestoreResources) {
+	HandleRestoreCommands(app.Command("restore", "Re-create missing reservations and persistent volumes from a snapshot"), q)
+}
+
+func HandleRestoreCommands(restore *kingpin.CmdClause, q *queries.RestoreResources) {
+	cmd := &restoreHandler{q: q}
+	restore.Action(cmd.handleRestore)
+	restore.Arg("file", "Snapshot file, or reserved_resources_full of a single agent").Required().StringVar(&cmd.file)
+	restore.Flag("agent-id", "Agent ID to restore. Required for a reserved_resources_full dump.").Default("").StringVar(&cmd.agentID)
+	restore.Flag("map-agent-id", "Restore reservations of an agent onto an agent with another ID, e.g. OLD-ID=NEW-ID. The new agent can also be given as a unique ID prefix, hostname or IP.").StringMapVar(&cmd.agentMap)
+	waitVar(restore, &cmd.wait)
+}
@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"github.com/minyk/dcos-resources/queries"
+	"gopkg.in/alecthomas/kingpin.v3-unstable"
+)
+
+type rolesHandler struct {
+	q        *queries.RoleList
+	role     string
+	reserved bool
+}
+
+func (cmd *rolesHandler) handleRoles(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.ListRoles(cmd.role, cmd.reserved)
+}
+
+// HandleRolesSection
+func HandleRolesSection(app *kingpin.Application, q *queries.RoleList) {
+	HandleRolesCommands(app.Command("roles", "List roles with their weights, frameworks and resources"), q)
+}
+
+func HandleRolesCommands(roles *kingpin.CmdClause, q *queries.RoleList) {
+	cmd := &rolesHandler{q: q}
+	roles.Action(cmd.handleRoles)
+	roles.Flag("role", "Only list this role and its children").Default("").StringVar(&cmd.role)
+	roles.Flag("reserved", "Sum up reserved resources of each role on all agents").BoolVar(&cmd.reserved)
+}
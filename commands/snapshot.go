@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"github.com/minyk/dcos-resources/queries"
+	"gopkg.in/alecthomas/kingpin.v3-unstable"
+)
+
+type snapshotHandler struct {
+	q      *queries.ResourceSnapshot
+	file   string
+	before string
+	after  string
+}
+
+func (cmd *snapshotHandler) handleSave(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.SaveSnapshot(cmd.file)
+}
+
+func (cmd *snapshotHandler) handleDiff(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.DiffSnapshot(cmd.before, cmd.after)
+}
+
+// HandleSnapshotSection
+func HandleSnapshotSection(app *kingpin.Application, q *queries.ResourceSnapshot) {
+	snapshot := app.Command("snapshot", "Save and compare reservations of all agents")
+	HandleSnapshotSaveCommand(snapshot.Command("save", "Save reservations and volumes of all agents to a file"), q)
+	HandleSnapshotDiffCommand(snapshot.Command("diff", "Show added, removed and changed reservations between two snapshots"), q)
+}
+
+func HandleSnapshotSaveCommand(save *kingpin.CmdClause, q *queries.ResourceSnapshot) {
+	cmd := &snapshotHandler{q: q}
+	save.Action(cmd.handleSave)
+	save.Arg("file", "Snapshot file to write").Required().StringVar(&cmd.file)
+}
+
+func HandleSnapshotDiffCommand(diff *kingpin.CmdClause, q *queries.ResourceSnapshot) {
+	cmd := &snapshotHandler{q: q}
+	diff.Action(cmd.handleDiff)
+	diff.Arg("before", "Snapshot file to compare from").Required().StringVar(&cmd.before)
+	diff.Arg("after", "Snapshot file to compare to. The live state of the cluster is used if omitted.").StringVar(&cmd.after)
+}
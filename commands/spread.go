@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"github.com/minyk/dcos-resources/queries"
+	"gopkg.in/alecthomas/kingpin.v3-unstable"
+)
+
+type spreadHandler struct {
+	q               *queries.SpreadReport
+	role            string
+	attribute       string
+	includeChildren bool
+}
+
+func (cmd *spreadHandler) handleSpread(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.Spread(cmd.role, cmd.attribute, cmd.includeChildren)
+}
+
+// HandleSpreadSection
+func HandleSpreadSection(app *kingpin.Application, q *queries.SpreadReport) {
+	HandleSpreadCommands(app.Command("spread", "Show how the reservations of a role are spread across fault domains"), q)
+}
+
+func HandleSpreadCommands(spread *kingpin.CmdClause, q *queries.SpreadReport) {
+	cmd := &spreadHandler{q: q}
+	spread.Action(cmd.handleSpread)
+	spread.Flag("role", "Role to report").Required().StringVar(&cmd.role)
+	spread.Flag("by", "Group by an attribute as well, e.g. rack").Default("").StringVar(&cmd.attribute)
+	spread.Flag("include-children", "Include the reservations of the children of the role").BoolVar(&cmd.includeChildren)
+}
@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"github.com/minyk/dcos-resources/queries"
+	"gopkg.in/alecthomas/kingpin.v3-unstable"
+)
+
+type summaryHandler struct {
+	q               *queries.ResourceSummary
+	agentID         string
+	role            string
+	includeChildren bool
+}
+
+func (cmd *summaryHandler) handleSummary(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.Summary(cmd.agentID, cmd.role, cmd.includeChildren)
+}
+
+// HandleSummarySection
+func HandleSummarySection(app *kingpin.Application, q *queries.ResourceSummary) {
+	HandleSummaryCommands(app.Command("summary", "Summarize reserved resources per role"), q)
+}
+
+func HandleSummaryCommands(summary *kingpin.CmdClause, q *queries.ResourceSummary) {
+	cmd := &summaryHandler{q: q}
+	summary.Action(cmd.handleSummary)
+	summary.Flag("agent-id", "Agent ID, unique ID prefix, hostname or IP of the agent to summarize. All agents are summarized if omitted.").Default("").StringVar(&cmd.agentID)
+	summary.Flag("role", "Role to summarize. All roles are summarized if omitted.").Default("").StringVar(&cmd.role)
+	summary.Flag("include-children", "Show the role tree with the totals of each role and its children").BoolVar(&cmd.includeChildren)
+}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"github.com/minyk/dcos-resources/queries"
+	"gopkg.in/alecthomas/kingpin.v3-unstable"
+	"strconv"
+)
+
+// megabytesValue is a flag value for memory and disk like 512, 512MiB, 4G or 1.5TiB, stored in MB.
+type megabytesValue float64
+
+func (v *megabytesValue) Set(s string) error {
+	mb, err := queries.ParseMegabytes(s)
+	if err != nil {
+		return err
+	}
+	*v = megabytesValue(mb)
+	return nil
+}
+
+func (v *megabytesValue) String() string {
+	return strconv.FormatFloat(float64(*v), 'f', -1, 64)
+}
+
+// cpusValue is a flag value for cpus like 2, 0.5 or 500m.
+type cpusValue float64
+
+func (v *cpusValue) Set(s string) error {
+	cpus, err := queries.ParseCPUs(s)
+	if err != nil {
+		return err
+	}
+	*v = cpusValue(cpus)
+	return nil
+}
+
+func (v *cpusValue) String() string {
+	return strconv.FormatFloat(float64(*v), 'f', -1, 64)
+}
+
+func megabytesVar(clause *kingpin.Clause, target *float64) {
+	clause.SetValue((*megabytesValue)(target))
+}
+
+func cpusVar(clause *kingpin.Clause, target *float64) {
+	clause.SetValue((*cpusValue)(target))
+}
+
+// amountValue is a flag value for an absolute amount or a percentage like 50%.
+type amountValue struct {
+	target *queries.Amount
+	parse  func(string) (float64, error)
+}
+
+func (v *amountValue) Set(s string) error {
+	amount, err := queries.ParseAmount(s, v.parse)
+	if err != nil {
+		return err
+	}
+	*v.target = amount
+	return nil
+}
+
+func (v *amountValue) String() string {
+	return v.target.String()
+}
+
+func cpusAmountVar(clause *kingpin.Clause, target *queries.Amount) {
+	clause.SetValue(&amountValue{target: target, parse: queries.ParseCPUs})
+}
+
+func megabytesAmountVar(clause *kingpin.Clause, target *queries.Amount) {
+	clause.SetValue(&amountValue{target: target, parse: queries.ParseMegabytes})
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"github.com/minyk/dcos-resources/queries"
 	"gopkg.in/alecthomas/kingpin.v3-unstable"
+	"time"
 )
 
 type unreserveResourceHandler struct {
@@ -20,18 +21,19 @@ type unreserveResourceHandler struct {
 	persistid     string
 	containerpath string
 	hostpath      string
+	wait          time.Duration
 }
 
 func (cmd *unreserveResourceHandler) handleUnreserve(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
-	return cmd.q.UnreserveResource(cmd.agentID, cmd.role, cmd.principal, cmd.cpus, cmd.cpuLabel, cmd.mem, cmd.memLabel, cmd.disk, cmd.diskLabel, cmd.frameworkID)
+	return cmd.q.UnreserveResource(cmd.agentID, cmd.role, cmd.principal, cmd.cpus, cmd.cpuLabel, cmd.mem, cmd.memLabel, cmd.disk, cmd.diskLabel, cmd.frameworkID, cmd.wait)
 }
 
 func (cmd *unreserveResourceHandler) handleUnreserveAll(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
-	return cmd.q.UnreserveResourceAll(cmd.agentID, cmd.role, cmd.principal)
+	return cmd.q.UnreserveResourceAll(cmd.agentID, cmd.role, cmd.principal, cmd.wait)
 }
 
 func (cmd *unreserveResourceHandler) handleDestroyPersistVolume(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
-	return cmd.q.DestroyVolume(cmd.agentID, cmd.role, cmd.principal, cmd.disk, cmd.diskLabel, cmd.frameworkID, cmd.persistid, cmd.containerpath, cmd.hostpath)
+	return cmd.q.DestroyVolume(cmd.agentID, cmd.role, cmd.principal, cmd.disk, cmd.diskLabel, cmd.frameworkID, cmd.persistid, cmd.containerpath, cmd.hostpath, cmd.wait)
 }
 
 // HandleScheduleSection
@@ -45,36 +47,39 @@ func HandleUnreserveResourcesSection(app *kingpin.Application, q *queries.Unrese
 func HandleUnreserveResourcesCommands(resources *kingpin.CmdClause, q *queries.UnreserveResources) {
 	cmd := &unreserveResourceHandler{q: q}
 	unReserve := resources.Action(cmd.handleUnreserve)
-	unReserve.Flag("agent-id", "Agent ID to unreserve").Required().StringVar(&cmd.agentID)
+	unReserve.Flag("agent-id", "Agent ID, unique ID prefix, hostname or IP of the agent to unreserve").Required().StringVar(&cmd.agentID)
 	unReserve.Flag("role", "Role for unreserve").Required().StringVar(&cmd.role)
 	unReserve.Flag("principal", "Principal for unreserve.").Default("my-principal").StringVar(&cmd.principal)
 	unReserve.Flag("framework-id", "Framework ID").Default("").StringVar(&cmd.frameworkID)
-	unReserve.Flag("cpus", "Amount of cpus to unreserve").Default("0").Float64Var(&cmd.cpus)
+	cpusVar(unReserve.Flag("cpus", "Amount of cpus to unreserve, e.g. 0.5 or 500m").Default("0"), &cmd.cpus)
 	unReserve.Flag("cpus-resource-id", "Resource id for unreserve action.").Default("").StringVar(&cmd.cpuLabel)
-	unReserve.Flag("mem", "Amount of memory to unreserve. The unit is MB.").Default("0").Float64Var(&cmd.mem)
+	megabytesVar(unReserve.Flag("mem", "Amount of memory to unreserve, e.g. 512MiB or 4G. Plain numbers are MB.").Default("0"), &cmd.mem)
 	unReserve.Flag("mem-resource-id", "Resource id for unreserve action.").Default("").StringVar(&cmd.memLabel)
-	unReserve.Flag("disk", "Amount of disk to unreserve").Default("0").Float64Var(&cmd.disk)
+	megabytesVar(unReserve.Flag("disk", "Amount of disk to unreserve, e.g. 512MiB or 4G. Plain numbers are MB.").Default("0"), &cmd.disk)
 	unReserve.Flag("disk-resource-id", "Resource id for unreserve action.").Default("").StringVar(&cmd.diskLabel)
+	waitVar(unReserve, &cmd.wait)
 }
 
 // Unreserve all resources with role and principal
 func HandleUnreserveResourcesAllCommands(resources *kingpin.CmdClause, q *queries.UnreserveResources) {
 	cmd := &unreserveResourceHandler{q: q}
 	unReserve := resources.Action(cmd.handleUnreserveAll)
-	unReserve.Flag("agent-id", "Agent ID to unreserve").Required().StringVar(&cmd.agentID)
+	unReserve.Flag("agent-id", "Agent ID, unique ID prefix, hostname or IP of the agent to unreserve").Required().StringVar(&cmd.agentID)
 	unReserve.Flag("role", "Role for unreserve").Required().StringVar(&cmd.role)
 	unReserve.Flag("principal", "Principal for unreservce").Required().StringVar(&cmd.principal)
+	waitVar(unReserve, &cmd.wait)
 }
 
 func HandleDestroyPersistVolume(resources *kingpin.CmdClause, q *queries.UnreserveResources) {
 	cmd := &unreserveResourceHandler{q: q}
 	destroyPersistVolume := resources.Action(cmd.handleDestroyPersistVolume)
-	destroyPersistVolume.Flag("agent-id", "Agent ID to unreserve").Required().StringVar(&cmd.agentID)
+	destroyPersistVolume.Flag("agent-id", "Agent ID, unique ID prefix, hostname or IP of the agent to unreserve").Required().StringVar(&cmd.agentID)
 	destroyPersistVolume.Flag("role", "Role for unreserve").Required().StringVar(&cmd.role)
 	destroyPersistVolume.Flag("principal", "Principal for unreserve.").Default("my-principal").StringVar(&cmd.principal)
-	destroyPersistVolume.Flag("disk", "Amount of disk to unreserve").Default("0").Float64Var(&cmd.disk)
+	megabytesVar(destroyPersistVolume.Flag("disk", "Amount of disk to unreserve, e.g. 512MiB or 4G. Plain numbers are MB.").Default("0"), &cmd.disk)
 	destroyPersistVolume.Flag("disk-resource-id", "Resource id for unreserve action.").Default("").StringVar(&cmd.diskLabel)
 	destroyPersistVolume.Flag("disk-persist-id", "Persistence id for unreserve action.").Default("").StringVar(&cmd.persistid)
 	destroyPersistVolume.Flag("container-path", "Container path of disk.").Default("").StringVar(&cmd.containerpath)
 	destroyPersistVolume.Flag("host-path", "host path of disk.").Default("").StringVar(&cmd.hostpath)
+	waitVar(destroyPersistVolume, &cmd.wait)
 }
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"gopkg.in/alecthomas/kingpin.v3-unstable"
+	"strconv"
+	"time"
+)
+
+// defaultWait is how long --wait waits for the agents to show a change without --wait-timeout.
+const defaultWait = time.Minute
+
+// waitValue is the flag value of --wait. It is a bool flag which waits defaultWait, unless --wait-timeout has set
+// how long to wait.
+type waitValue struct {
+	target *time.Duration
+}
+
+func (v *waitValue) Set(s string) error {
+	wait, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	if !wait {
+		*v.target = 0
+	} else if *v.target == 0 {
+		*v.target = defaultWait
+	}
+	return nil
+}
+
+func (v *waitValue) String() string {
+	return strconv.FormatBool(*v.target > 0)
+}
+
+func (v *waitValue) IsBoolFlag() bool {
+	return true
+}
+
+// waitVar adds the --wait and --wait-timeout flags to a command which changes reservations or volumes.
+func waitVar(command *kingpin.CmdClause, target *time.Duration) {
+	command.Flag("wait", "Wait for the agent state to show the change, for up to a minute unless --wait-timeout is given").SetValue(&waitValue{target: target})
+	command.Flag("wait-timeout", "Wait up to this long for the agent state to show the change, e.g. 30s").DurationVar(target)
+}
@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"github.com/minyk/dcos-resources/queries"
+	"gopkg.in/alecthomas/kingpin.v3-unstable"
+)
+
+type weightsHandler struct {
+	q      *queries.RoleWeights
+	role   string
+	weight float64
+}
+
+func (cmd *weightsHandler) handleList(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.ListWeights()
+}
+
+func (cmd *weightsHandler) handleSet(a *kingpin.Application, e *kingpin.ParseElement, c *kingpin.ParseContext) error {
+	return cmd.q.SetWeight(cmd.role, cmd.weight)
+}
+
+// HandleWeightsSection
+func HandleWeightsSection(app *kingpin.Application, q *queries.RoleWeights) {
+	weights := app.Command("weights", "Manage weights of roles")
+	HandleWeightsListCommand(weights.Command("list", "List weights of roles"), q)
+	HandleWeightsSetCommand(weights.Command("set", "Set weight of a role"), q)
+}
+
+func HandleWeightsListCommand(list *kingpin.CmdClause, q *queries.RoleWeights) {
+	cmd := &weightsHandler{q: q}
+	list.Action(cmd.handleList)
+}
+
+func HandleWeightsSetCommand(set *kingpin.CmdClause, q *queries.RoleWeights) {
+	cmd := &weightsHandler{q: q}
+	set.Action(cmd.handleSet)
+	set.Flag("role", "Role to set weight").Required().StringVar(&cmd.role)
+	set.Flag("weight", "Weight of the role").Required().Float64Var(&cmd.weight)
+}
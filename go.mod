@@ -3,7 +3,7 @@ module github.com/minyk/dcos-resources
 require (
 	github.com/BurntSushi/toml v0.3.1
 	github.com/alecthomas/units v0.0.0-20151022065526-2efee857e7cf
-	github.com/gogo/protobuf v1.2.0 // indirect
+	github.com/gogo/protobuf v1.2.0
 	github.com/mesos/mesos-go v0.0.11-0.20190717023829-56ac038085ac
 	github.com/mesosphere/dcos-commons v0.0.0-20180809221131-a0a11ce20f4b
 	github.com/nicksnyder/go-i18n v1.8.1
@@ -0,0 +1,142 @@
+package queries
+
+import (
+	"errors"
+	"github.com/gogo/protobuf/types"
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	mastercalls "github.com/mesos/mesos-go/api/v1/lib/master/calls"
+	"github.com/minyk/dcos-resources/client"
+	"time"
+)
+
+const drainPollInterval = 5 * time.Second
+
+type AgentDrain struct {
+	PrefixMesosMasterApiV1 func() string
+	PrefixMesosSlaveApiV0  func(string) string
+	PrefixMesosSlaveApiV1  func(string) string
+}
+
+func NewAgentDrain() *AgentDrain {
+	return &AgentDrain{
+		PrefixMesosMasterApiV1: func() string { return "/mesos/api/v1/" },
+		PrefixMesosSlaveApiV0:  func(agentid string) string { return "/agent/" + agentid },
+		PrefixMesosSlaveApiV1:  func(agentid string) string { return "/agent/" + agentid + "/api/v1" },
+	}
+}
+
+// Drain drains an agent. A zero maxGracePeriod leaves the kill policies of tasks as they are. If unreserve is set,
+// it waits until no executors are left on the agent and unreserves its reservations. Persistent volumes are
+// reported and left untouched.
+func (q *AgentDrain) Drain(agentid string, maxGracePeriod time.Duration, markGone bool, unreserve bool, timeout time.Duration, wait time.Duration) error {
+	agentid, err := resolveAgent(q.PrefixMesosMasterApiV1(), agentid)
+	if err != nil {
+		return err
+	}
+
+	_, err = callMaster(q.PrefixMesosMasterApiV1(), mastercalls.DrainAgent(mesos.AgentID{Value: agentid}, func(c *master.Call_DrainAgent) {
+		if maxGracePeriod > 0 {
+			c.MaxGracePeriod = types.DurationProto(maxGracePeriod)
+		}
+		c.MarkGone = &markGone
+	}))
+	if err != nil {
+		return err
+	}
+
+	client.PrintMessage("Draining agent %s is started.", agentid)
+
+	if !unreserve {
+		return nil
+	}
+
+	err = q.waitForExecutors(agentid, timeout)
+	if err != nil {
+		return err
+	}
+
+	return q.unreserveAll(agentid, wait)
+}
+
+func (q *AgentDrain) Deactivate(agentid string) error {
+	agentid, err := resolveAgent(q.PrefixMesosMasterApiV1(), agentid)
+	if err != nil {
+		return err
+	}
+
+	_, err = callMaster(q.PrefixMesosMasterApiV1(), mastercalls.DeactivateAgent(mesos.AgentID{Value: agentid}))
+	if err != nil {
+		return err
+	}
+
+	client.PrintMessage("Deactivating agent %s is successful.", agentid)
+
+	return nil
+}
+
+func (q *AgentDrain) Reactivate(agentid string) error {
+	agentid, err := resolveAgent(q.PrefixMesosMasterApiV1(), agentid)
+	if err != nil {
+		return err
+	}
+
+	_, err = callMaster(q.PrefixMesosMasterApiV1(), mastercalls.ReactivateAgent(mesos.AgentID{Value: agentid}))
+	if err != nil {
+		return err
+	}
+
+	client.PrintMessage("Reactivating agent %s is successful.", agentid)
+
+	return nil
+}
+
+// waitForExecutors polls the executors of an agent until none are left or timeout has passed.
+func (q *AgentDrain) waitForExecutors(agentid string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		executors, err := getExecutors(q.PrefixMesosSlaveApiV1(agentid))
+		if err != nil {
+			return err
+		}
+		if len(executors) == 0 {
+			client.PrintMessage("No executors are left on agent %s.", agentid)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.New("timed out waiting for executors on agent " + agentid + " to exit")
+		}
+
+		client.PrintMessage("Waiting for %d executors on agent %s to exit...", len(executors), agentid)
+		time.Sleep(drainPollInterval)
+	}
+}
+
+func (q *AgentDrain) unreserveAll(agentid string, wait time.Duration) error {
+	resourcesFull, err := listResources(q.PrefixMesosSlaveApiV0(agentid))
+	if err != nil {
+		return err
+	}
+
+	var removed []mesos.Resource
+	for role, resources := range resourcesFull {
+		for _, r := range resources {
+			rid, _ := getIDsFromLabels(r.GetReservation().GetLabels().GetLabels())
+			if r.GetDisk().GetPersistence().GetID() != "" {
+				client.PrintMessage("Skipped persistent volume %s of %s: %s", r.GetDisk().GetPersistence().GetID(), role, rid)
+				continue
+			}
+
+			err = unreserveResources(q.PrefixMesosMasterApiV1(), agentid, r)
+			if err != nil {
+				return err
+			}
+			removed = append(removed, r)
+			client.PrintMessage("Unreserved %s %s of %s: %s", r.GetName(), resourceValue(r), role, rid)
+		}
+	}
+
+	client.PrintMessage("Unreservation is successful.")
+
+	return waitForChange(q.PrefixMesosSlaveApiV0(agentid), agentid, wait, resourcesFull, nil, removed)
+}
@@ -0,0 +1,121 @@
+package queries
+
+import (
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	"github.com/minyk/dcos-resources/client"
+	"sort"
+	"strings"
+)
+
+type AgentList struct {
+	PrefixMesosMasterApiV1 func() string
+	PrefixMesosSlaveApiV0  func(string) string
+	PrefixMesosSlaveApiV1  func(string) string
+}
+
+func NewAgentList() *AgentList {
+	return &AgentList{
+		PrefixMesosMasterApiV1: func() string { return "/mesos/api/v1/" },
+		PrefixMesosSlaveApiV0:  func(agentid string) string { return "/agent/" + agentid },
+		PrefixMesosSlaveApiV1:  func(agentid string) string { return "/agent/" + agentid + "/api/v1" },
+	}
+}
+
+// ListAgents prints every agent with its state, fault domain and attributes, followed by its total, unreserved,
+// reserved per role and allocated resources.
+func (q *AgentList) ListAgents() error {
+	agents, err := getAgents(q.PrefixMesosMasterApiV1())
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(agents, func(i, j int) bool {
+		return agents[i].AgentInfo.Hostname < agents[j].AgentInfo.Hostname
+	})
+
+	client.PrintMessage("Hostname\t\tAgentID\t\tState\t\tDomain\t\tAttributes")
+	for _, agent := range agents {
+		client.PrintMessage("%s\t\t%s\t\t%s\t\t%s\t\t%s", agent.AgentInfo.Hostname, agent.AgentInfo.ID.Value, agentStatus(agent), agentDomain(agent), agentAttributes(agent))
+
+		total := make(map[string]float64)
+		unreserved := make(map[string]float64)
+		reserved := make(map[string]map[string]float64)
+		for _, r := range agent.GetTotalResources() {
+			if r.GetType() != mesos.SCALAR {
+				continue
+			}
+			total[r.GetName()] += r.GetScalar().GetValue()
+
+			role := r.ReservationRole()
+			if role == "*" {
+				unreserved[r.GetName()] += r.GetScalar().GetValue()
+				continue
+			}
+			if reserved[role] == nil {
+				reserved[role] = make(map[string]float64)
+			}
+			reserved[role][r.GetName()] += r.GetScalar().GetValue()
+		}
+
+		allocated := make(map[string]float64)
+		for _, r := range agent.GetAllocatedResources() {
+			if r.GetType() == mesos.SCALAR {
+				allocated[r.GetName()] += r.GetScalar().GetValue()
+			}
+		}
+
+		var roles []string
+		for role := range reserved {
+			roles = append(roles, role)
+		}
+		sort.Strings(roles)
+
+		client.PrintMessage("  Total\t\t%s", formatScalars(total))
+		client.PrintMessage("  Unreserved\t\t%s", formatScalars(unreserved))
+		for _, role := range roles {
+			client.PrintMessage("  Reserved(%s)\t\t%s", role, formatScalars(reserved[role]))
+		}
+		client.PrintMessage("  Allocated\t\t%s", formatScalars(allocated))
+	}
+
+	return nil
+}
+
+func agentStatus(agent master.Response_GetAgents_Agent) string {
+	switch {
+	case agent.GetDrainInfo() != nil:
+		return agent.GetDrainInfo().GetState().String()
+	case agent.GetDeactivated():
+		return "DEACTIVATED"
+	case agent.GetActive():
+		return "ACTIVE"
+	default:
+		return "INACTIVE"
+	}
+}
+
+// agentDomain formats the fault domain of an agent as region/zone.
+func agentDomain(agent master.Response_GetAgents_Agent) string {
+	faultDomain := agent.AgentInfo.GetDomain().GetFaultDomain()
+	if faultDomain == nil {
+		return "-"
+	}
+	return faultDomain.Region.Name + "/" + faultDomain.Zone.Name
+}
+
+func agentAttributes(agent master.Response_GetAgents_Agent) string {
+	var attributes []string
+	for _, a := range agent.AgentInfo.GetAttributes() {
+		attributes = append(attributes, a.GetName()+":"+attributeValue(a))
+	}
+	sort.Strings(attributes)
+	return strings.Join(attributes, ",")
+}
+
+func attributeValue(a mesos.Attribute) string {
+	if a.GetType() == mesos.TEXT {
+		return a.GetText().GetValue()
+	}
+	return resourceValue(mesos.Resource{Type: a.Type.Enum(), Scalar: a.Scalar, Ranges: a.Ranges, Set: a.Set})
+}
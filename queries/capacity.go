@@ -0,0 +1,232 @@
+package queries
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	"github.com/minyk/dcos-resources/client"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type CapacityPlanner struct {
+	PrefixMesosMasterApiV1 func() string
+	PrefixMesosSlaveApiV0  func(string) string
+	PrefixMesosSlaveApiV1  func(string) string
+}
+
+func NewCapacityPlanner() *CapacityPlanner {
+	return &CapacityPlanner{
+		PrefixMesosMasterApiV1: func() string { return "/mesos/api/v1/" },
+		PrefixMesosSlaveApiV0:  func(agentid string) string { return "/agent/" + agentid },
+		PrefixMesosSlaveApiV1:  func(agentid string) string { return "/agent/" + agentid + "/api/v1" },
+	}
+}
+
+type capacityCandidate struct {
+	agent  master.Response_GetAgents_Agent
+	free   map[string]float64
+	score  float64
+	domain string
+	before ReservedResourcesFull
+}
+
+// FindCapacity prints count agents whose unreserved, unallocated resources can hold the request, best fit first.
+// Disks are fitted from the root disk. With spread, the agents are spread as evenly as possible across the values
+// of a fault domain level or an attribute. If role is set, the resources are reserved for it on the chosen agents
+// after the same checks as reserve. Agents which can not be reached are skipped.
+func (q *CapacityPlanner) FindCapacity(cpus float64, mem float64, disk float64, count int, spread string, role string, principal string, enforceQuota bool, wait time.Duration) error {
+	var specs []string
+	if disk > 0 {
+		specs = append(specs, "disk:scalar:"+strconv.FormatFloat(disk, 'f', -1, 64))
+	}
+	resources, err := reservation(role, principal, cpus, mem, specs)
+	if err != nil {
+		return errors.New("no resources are requested")
+	}
+
+	agents, err := getAgents(q.PrefixMesosMasterApiV1())
+	if err != nil {
+		return err
+	}
+
+	var candidates []capacityCandidate
+	var unreachable []string
+	for _, agent := range agents {
+		state, err := getAgentState(q.PrefixMesosSlaveApiV0(agent.AgentInfo.ID.Value))
+		if err != nil {
+			client.PrintMessage("Skipped agent %s: %s", agent.AgentInfo.ID.Value, err)
+			unreachable = append(unreachable, agent.AgentInfo.ID.Value)
+			continue
+		}
+
+		free, score, fits := fitScore(availableResources(agent, state), resources)
+		if !fits {
+			continue
+		}
+
+		candidates = append(candidates, capacityCandidate{agent: agent, free: free, score: score, domain: spreadValue(agent, spread), before: state.AgentReservedResourcesFull})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score < candidates[j].score
+	})
+
+	chosen := spreadCandidates(candidates, count, spread != "")
+	if len(chosen) < count {
+		if len(unreachable) > 0 {
+			return fmt.Errorf("%d agents have the capacity, %d requested, %d agents could not be reached", len(chosen), count, len(unreachable))
+		}
+		return fmt.Errorf("%d agents have the capacity, %d requested", len(chosen), count)
+	}
+
+	client.PrintMessage("Hostname\t\tAgentID\t\tDomain\t\tFree\t\tScore")
+	for _, c := range chosen {
+		client.PrintMessage("%s\t\t%s\t\t%s\t\t%s\t\t%f", c.agent.AgentInfo.Hostname, c.agent.AgentInfo.ID.Value, c.domain, formatScalars(c.free), c.score)
+	}
+
+	if role == "" {
+		return nil
+	}
+
+	var total []mesos.Resource
+	for range chosen {
+		total = append(total, resources...)
+	}
+	err = warnOrEnforceQuota(q.PrefixMesosMasterApiV1(), role, enforceQuota, total...)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, c := range chosen {
+		agentid := c.agent.AgentInfo.ID.Value
+		err = q.reserveOn(c.agent, resources, wait)
+		if err != nil {
+			client.PrintMessage("Reservation on %s failed: %s", agentid, err)
+			failed++
+			continue
+		}
+		client.PrintMessage("Reservation on %s is successful.", agentid)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("reservation failed on %d of %d agents", failed, len(chosen))
+	}
+
+	return nil
+}
+
+// reserveOn reserves the resources on a chosen agent after checking them against what it advertises and against
+// its current state, which may have changed since the agent was chosen.
+func (q *CapacityPlanner) reserveOn(agent master.Response_GetAgents_Agent, resources []mesos.Resource, wait time.Duration) error {
+	agentid := agent.AgentInfo.ID.Value
+
+	err := validateResources(agent, true, resources...)
+	if err != nil {
+		return err
+	}
+
+	state, err := getAgentState(q.PrefixMesosSlaveApiV0(agentid))
+	if err != nil {
+		return err
+	}
+
+	err = checkCapacity(agentid, availableResources(agent, state), resources...)
+	if err != nil {
+		return err
+	}
+
+	err = reserveResources(q.PrefixMesosMasterApiV1(), agentid, resources...)
+	if err != nil {
+		return err
+	}
+
+	err = waitForChange(q.PrefixMesosSlaveApiV0(agentid), agentid, wait, state.AgentReservedResourcesFull, resources, nil)
+	if err != nil {
+		return fmt.Errorf("not confirmed: %s", err)
+	}
+
+	return nil
+}
+
+// fitScore reports whether the available resources can hold the requested ones, with the free amount of each
+// requested resource from the same disk source and a score. The score is the share of the free resources left
+// over, so the tightest fit comes first.
+func fitScore(available ResourceRole, resources []mesos.Resource) (map[string]float64, float64, bool) {
+	if checkCapacity("", available, resources...) != nil {
+		return nil, 0, false
+	}
+
+	free := make(map[string]float64)
+	score := 0.0
+	for _, r := range resources {
+		free[r.GetName()] = sourceTotal(available, r)
+		score += (free[r.GetName()] - r.GetScalar().GetValue()) / free[r.GetName()]
+	}
+	return free, score, true
+}
+
+// freeScalars returns the unreserved scalar resources of an agent which are not allocated to a framework.
+func freeScalars(agent master.Response_GetAgents_Agent, state AgentState) map[string]float64 {
+	free := make(map[string]float64)
+	for _, r := range availableResources(agent, state) {
+		if r.GetType() == mesos.SCALAR {
+			free[r.GetName()] += r.GetScalar().GetValue()
+		}
+	}
+	return free
+}
+
+// spreadValue returns the value of an agent for a spread key, or its region/zone if key is empty.
+func spreadValue(agent master.Response_GetAgents_Agent, key string) string {
+	if key == "" {
+		return agentDomain(agent)
+	}
+	if value := agentLabel(agent, key); value != "" {
+		return value
+	}
+	return "-"
+}
+
+// spreadCandidates picks count candidates in order. With spread, the candidates are picked round robin across
+// their domains, so no domain gets a second agent before every domain has one.
+func spreadCandidates(candidates []capacityCandidate, count int, spread bool) []capacityCandidate {
+	if !spread {
+		if len(candidates) > count {
+			return candidates[:count]
+		}
+		return candidates
+	}
+
+	var domains []string
+	groups := make(map[string][]capacityCandidate)
+	for _, c := range candidates {
+		if _, ok := groups[c.domain]; !ok {
+			domains = append(domains, c.domain)
+		}
+		groups[c.domain] = append(groups[c.domain], c)
+	}
+
+	var chosen []capacityCandidate
+	for len(chosen) < count {
+		picked := false
+		for _, domain := range domains {
+			if len(chosen) == count {
+				break
+			}
+			if len(groups[domain]) == 0 {
+				continue
+			}
+			chosen = append(chosen, groups[domain][0])
+			groups[domain] = groups[domain][1:]
+			picked = true
+		}
+		if !picked {
+			break
+		}
+	}
+
+	return chosen
+}
@@ -0,0 +1,141 @@
+package queries
+
+import (
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	"reflect"
+	"testing"
+)
+
+func TestSpreadCandidates(t *testing.T) {
+	candidate := func(id string, domain string) capacityCandidate {
+		return capacityCandidate{agent: testAgent(id, id, "", "", nil), domain: domain}
+	}
+	candidates := []capacityCandidate{
+		candidate("S0", "a"),
+		candidate("S1", "a"),
+		candidate("S2", "a"),
+		candidate("S3", "b"),
+		candidate("S4", "c"),
+		candidate("S5", "b"),
+	}
+
+	tests := []struct {
+		name   string
+		count  int
+		spread bool
+		want   []string
+	}{
+		{"in order", 3, false, []string{"S0", "S1", "S2"}},
+		{"in order, fewer than count", 10, false, []string{"S0", "S1", "S2", "S3", "S4", "S5"}},
+		{"one per domain", 3, true, []string{"S0", "S3", "S4"}},
+		{"second round", 5, true, []string{"S0", "S3", "S4", "S1", "S5"}},
+		{"all domains exhausted", 10, true, []string{"S0", "S3", "S4", "S1", "S5", "S2"}},
+		{"none", 0, true, nil},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, c := range spreadCandidates(candidates, tt.count, tt.spread) {
+			got = append(got, c.agent.AgentInfo.ID.Value)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: spreadCandidates() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSpreadValue(t *testing.T) {
+	agent := testAgent("S0", "node-a", "10.0.0.1", "us-east-1a", map[string]string{"rack": "r1"})
+	noDomain := testAgent("S1", "node-b", "10.0.0.2", "", nil)
+
+	tests := []struct {
+		agent master.Response_GetAgents_Agent
+		key   string
+		want  string
+	}{
+		{agent, "", "us-east-1/us-east-1a"},
+		{agent, "zone", "us-east-1a"},
+		{agent, "rack", "r1"},
+		{agent, "os", "-"},
+		{noDomain, "", "-"},
+		{noDomain, "region", "-"},
+	}
+
+	for _, tt := range tests {
+		if got := spreadValue(tt.agent, tt.key); got != tt.want {
+			t.Errorf("spreadValue(%s, %q) = %q, want %q", tt.agent.AgentInfo.Hostname, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestFitScore(t *testing.T) {
+	root := unreserved("disk", 1000)
+	mount := mountDisk(unreserved("disk", 500000), "/dcos/volume0")
+
+	tests := []struct {
+		name      string
+		available ResourceRole
+		resources []mesos.Resource
+		wantFree  map[string]float64
+		wantScore float64
+		wantFits  bool
+	}{
+		{"tight fit", ResourceRole{unreserved("cpus", 4), unreserved("mem", 1024)},
+			[]mesos.Resource{resource("cpus", "r", "p", 4), resource("mem", "r", "p", 512)},
+			map[string]float64{"cpus": 4, "mem": 1024}, 0.5, true},
+		{"cpus short", ResourceRole{unreserved("cpus", 1), unreserved("mem", 1024)},
+			[]mesos.Resource{resource("cpus", "r", "p", 2), resource("mem", "r", "p", 512)},
+			nil, 0, false},
+		{"root disk", ResourceRole{root, mount},
+			[]mesos.Resource{resource("disk", "r", "p", 500)},
+			map[string]float64{"disk": 1000}, 0.5, true},
+		{"root disk short beside mount disk", ResourceRole{root, mount},
+			[]mesos.Resource{resource("disk", "r", "p", 2000)},
+			nil, 0, false},
+		{"mount disk only", ResourceRole{mount},
+			[]mesos.Resource{resource("disk", "r", "p", 1)},
+			nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		free, score, fits := fitScore(tt.available, tt.resources)
+		if fits != tt.wantFits || score != tt.wantScore || !reflect.DeepEqual(free, tt.wantFree) {
+			t.Errorf("%s: fitScore() = %v, %v, %v, want %v, %v, %v", tt.name, free, score, fits, tt.wantFree, tt.wantScore, tt.wantFits)
+		}
+	}
+}
+
+func TestFindCapacity(t *testing.T) {
+	agents := []master.Response_GetAgents_Agent{
+		testAgent("S0", "node-a", "10.0.0.1", "us-east-1a", nil),
+		testAgent("S1", "node-b", "10.0.0.2", "us-east-1b", nil),
+		testAgent("S2", "node-c", "10.0.0.3", "us-east-1b", nil),
+	}
+	states := map[string]AgentState{
+		"S0": {AgentUnreservedResourcesFull: ResourceRole{unreserved("cpus", 4), unreserved("disk", 1000)}},
+		"S2": {AgentUnreservedResourcesFull: ResourceRole{unreserved("cpus", 4), unreserved("disk", 100), mountDisk(unreserved("disk", 500000), "/dcos/volume0")}},
+	}
+	defer testCluster(map[master.Call_Type]master.Response{master.Call_GET_AGENTS: agentsResponse(agents...)}, states)()
+	q := NewCapacityPlanner()
+
+	tests := []struct {
+		name    string
+		cpus    float64
+		disk    float64
+		count   int
+		wantErr bool
+	}{
+		{"unreachable agent is skipped", 2, 0, 2, false},
+		{"unreachable agent is not counted", 2, 0, 3, true},
+		{"root disk", 2, 500, 1, false},
+		{"mount disk does not hold root disk", 2, 500, 2, true},
+	}
+
+	for _, tt := range tests {
+		err := q.FindCapacity(tt.cpus, 0, tt.disk, tt.count, "", "", "p", false, 0)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: FindCapacity() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
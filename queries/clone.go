@@ -0,0 +1,200 @@
+package queries
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	"github.com/minyk/dcos-resources/client"
+	"time"
+)
+
+type CloneResources struct {
+	PrefixMesosMasterApiV1 func() string
+	PrefixMesosSlaveApiV0  func(string) string
+	PrefixMesosSlaveApiV1  func(string) string
+}
+
+func NewCloneResources() *CloneResources {
+	return &CloneResources{
+		PrefixMesosMasterApiV1: func() string { return "/mesos/api/v1/" },
+		PrefixMesosSlaveApiV0:  func(agentid string) string { return "/agent/" + agentid },
+		PrefixMesosSlaveApiV1:  func(agentid string) string { return "/agent/" + agentid + "/api/v1" },
+	}
+}
+
+// Clone reserves the reservation layout of the template agent on each of the target agents. Resource IDs and
+// persistence IDs are removed, or replaced with new ones if regenerate is set.
+func (q *CloneResources) Clone(from string, to []string, role string, regenerate bool, wait time.Duration) error {
+	agents, err := getAgents(q.PrefixMesosMasterApiV1())
+	if err != nil {
+		return err
+	}
+	from, targets, err := cloneAgents(agents, from, to)
+	if err != nil {
+		return err
+	}
+
+	template, err := listResources(q.PrefixMesosSlaveApiV0(from))
+	if err != nil {
+		return err
+	}
+
+	var resources ResourceRole
+	for r, roleResources := range template {
+		if role == "" || r == role {
+			resources = append(resources, roleResources...)
+		}
+	}
+	if len(resources) == 0 {
+		return errors.New("no resources are reserved on template agent")
+	}
+
+	failed := 0
+	for _, agent := range targets {
+		agentid := agent.AgentInfo.ID.Value
+		err = q.cloneTo(agent, resources, regenerate, wait)
+		if err != nil {
+			client.PrintMessage("Cloning onto %s failed: %s", agentid, err)
+			failed++
+		} else {
+			client.PrintMessage("Cloning onto %s is successful.", agentid)
+		}
+	}
+
+	if failed > 0 {
+		return errors.New("cloning failed on some agents")
+	}
+
+	return nil
+}
+
+// cloneAgents resolves the template agent and the target agents. Targets must differ from the template and from
+// each other, or the layout would be reserved twice on the same agent.
+func cloneAgents(agents []master.Response_GetAgents_Agent, from string, to []string) (string, []master.Response_GetAgents_Agent, error) {
+	from, err := matchAgent(agents, from)
+	if err != nil {
+		return "", nil, err
+	}
+
+	seen := map[string]bool{from: true}
+	var targets []master.Response_GetAgents_Agent
+	for _, target := range to {
+		agentid, err := matchAgent(agents, target)
+		if err != nil {
+			return "", nil, err
+		}
+		if agentid == from {
+			return "", nil, fmt.Errorf("target agent %s is the template agent", target)
+		}
+		if seen[agentid] {
+			return "", nil, fmt.Errorf("target agent %s is given twice", target)
+		}
+		seen[agentid] = true
+
+		for _, a := range agents {
+			if a.AgentInfo.ID.Value == agentid {
+				targets = append(targets, a)
+			}
+		}
+	}
+
+	return from, targets, nil
+}
+
+func (q *CloneResources) cloneTo(agent master.Response_GetAgents_Agent, template ResourceRole, regenerate bool, wait time.Duration) error {
+	agentid := agent.AgentInfo.ID.Value
+	var reservations, volumes ResourceRole
+	for _, r := range template {
+		clone := cloneResource(r, regenerate)
+		reservations = append(reservations, withoutVolume(clone))
+		if clone.GetDisk().GetPersistence().GetID() != "" {
+			volumes = append(volumes, clone)
+		}
+	}
+
+	state, err := getAgentState(q.PrefixMesosSlaveApiV0(agentid))
+	if err != nil {
+		return err
+	}
+
+	err = checkCapacity(agentid, availableResources(agent, state), reservations...)
+	if err != nil {
+		return err
+	}
+
+	err = reserveResources(q.PrefixMesosMasterApiV1(), agentid, reservations...)
+	if err != nil {
+		return err
+	}
+
+	if len(volumes) > 0 {
+		err = createVolumes(q.PrefixMesosMasterApiV1(), agentid, volumes...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return waitForChange(q.PrefixMesosSlaveApiV0(agentid), agentid, wait, state.AgentReservedResourcesFull, append(reservations, volumes...), nil)
+}
+
+// cloneResource returns a copy of a reserved resource without the IDs specific to the agent it was reserved on.
+func cloneResource(r mesos.Resource, regenerate bool) mesos.Resource {
+	r.AllocationInfo = nil
+
+	rid := ""
+	if regenerate {
+		rid = newUUID()
+	}
+
+	if r.Reservation != nil {
+		reservation := *r.Reservation
+		reservation.Labels = cloneLabels(reservation.Labels, rid)
+		r.Reservation = &reservation
+	}
+
+	var reservations []mesos.Resource_ReservationInfo
+	for _, reservation := range r.Reservations {
+		reservation.Labels = cloneLabels(reservation.Labels, rid)
+		reservations = append(reservations, reservation)
+	}
+	r.Reservations = reservations
+
+	if r.GetDisk().GetPersistence().GetID() != "" {
+		if !regenerate {
+			return withoutVolume(r)
+		}
+		disk := *r.Disk
+		persistence := *disk.Persistence
+		persistence.ID = newUUID()
+		disk.Persistence = &persistence
+		r.Disk = &disk
+	}
+
+	return r
+}
+
+// cloneLabels copies labels, replacing the resource_id label with rid or dropping it if rid is empty.
+func cloneLabels(labels *mesos.Labels, rid string) *mesos.Labels {
+	if labels == nil {
+		return nil
+	}
+
+	var cloned []mesos.Label
+	for _, label := range labels.GetLabels() {
+		if label.Key == "resource_id" {
+			if rid == "" {
+				continue
+			}
+			value := rid
+			label.Value = &value
+		}
+		cloned = append(cloned, label)
+	}
+
+	if len(cloned) == 0 {
+		return nil
+	}
+
+	return &mesos.Labels{Labels: cloned}
+}
@@ -0,0 +1,131 @@
+package queries
+
+import (
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	"reflect"
+	"testing"
+)
+
+// labeled returns r with its reservations labeled with a resource ID and an owner.
+func labeled(r mesos.Resource, rid string) mesos.Resource {
+	owner := "owner"
+	labels := &mesos.Labels{Labels: []mesos.Label{{Key: "resource_id", Value: &rid}, {Key: "owner", Value: &owner}}}
+	reservation := *r.Reservation
+	reservation.Labels = labels
+	r.Reservation = &reservation
+	r.Reservations = []mesos.Resource_ReservationInfo{{Type: mesos.Resource_ReservationInfo_DYNAMIC.Enum(), Role: r.Role, Principal: reservation.Principal, Labels: labels}}
+	r.AllocationInfo = &mesos.Resource_AllocationInfo{Role: r.Role}
+	return r
+}
+
+// volume returns a persistent volume with id on a disk reserved for role.
+func volume(role string, value float64, id string) mesos.Resource {
+	r := resource("disk", role, "p", value)
+	r.Disk = &mesos.Resource_DiskInfo{Persistence: &mesos.Resource_DiskInfo_Persistence{ID: id}}
+	return r
+}
+
+func TestCloneResource(t *testing.T) {
+	tests := []struct {
+		name       string
+		r          mesos.Resource
+		regenerate bool
+		wantLabels int
+	}{
+		{"reservation", labeled(resource("cpus", "r", "p", 1), "rid-1"), false, 1},
+		{"reservation with new id", labeled(resource("cpus", "r", "p", 1), "rid-1"), true, 2},
+		{"volume", labeled(volume("r", 100, "v1"), "rid-2"), false, 1},
+		{"volume with new id", labeled(volume("r", 100, "v1"), "rid-2"), true, 2},
+		{"no labels", resource("mem", "r", "p", 1024), true, 0},
+	}
+
+	for _, tt := range tests {
+		rid, _ := getIDsFromLabels(tt.r.GetReservation().GetLabels().GetLabels())
+		volumeID := tt.r.GetDisk().GetPersistence().GetID()
+		cloned := cloneResource(tt.r, tt.regenerate)
+
+		if cloned.AllocationInfo != nil {
+			t.Errorf("%s: cloneResource() kept the allocation info", tt.name)
+		}
+		if cloned.ReservationRole() != tt.r.ReservationRole() || resourceValue(cloned) != resourceValue(tt.r) {
+			t.Errorf("%s: cloneResource() = %s %s for %s, want %s %s for %s", tt.name, cloned.GetName(), resourceValue(cloned), cloned.ReservationRole(), tt.r.GetName(), resourceValue(tt.r), tt.r.ReservationRole())
+		}
+
+		clonedRid, _ := getIDsFromLabels(cloned.GetReservation().GetLabels().GetLabels())
+		for _, reservation := range cloned.Reservations {
+			if stackRid, _ := getIDsFromLabels(reservation.GetLabels().GetLabels()); stackRid != clonedRid {
+				t.Errorf("%s: cloneResource() resource ID %q in the reservation stack, %q in the reservation", tt.name, stackRid, clonedRid)
+			}
+		}
+		switch {
+		case !tt.regenerate && clonedRid != "":
+			t.Errorf("%s: cloneResource() kept resource ID %q", tt.name, clonedRid)
+		case tt.regenerate && rid != "" && (clonedRid == "" || clonedRid == rid):
+			t.Errorf("%s: cloneResource() resource ID = %q, want a new one", tt.name, clonedRid)
+		}
+		if labels := cloned.GetReservation().GetLabels().GetLabels(); len(labels) != tt.wantLabels {
+			t.Errorf("%s: cloneResource() labels = %v, want %d", tt.name, labels, tt.wantLabels)
+		}
+
+		clonedVolumeID := cloned.GetDisk().GetPersistence().GetID()
+		switch {
+		case !tt.regenerate && clonedVolumeID != "":
+			t.Errorf("%s: cloneResource() kept volume %q", tt.name, clonedVolumeID)
+		case tt.regenerate && volumeID != "" && (clonedVolumeID == "" || clonedVolumeID == volumeID):
+			t.Errorf("%s: cloneResource() volume ID = %q, want a new one", tt.name, clonedVolumeID)
+		}
+	}
+}
+
+func TestCloneAgents(t *testing.T) {
+	agents := []master.Response_GetAgents_Agent{
+		testAgent("S0", "node-a", "10.0.0.1", "", nil),
+		testAgent("S1", "node-b", "10.0.0.2", "", nil),
+		testAgent("S2", "node-c", "10.0.0.3", "", nil),
+	}
+
+	tests := []struct {
+		name        string
+		from        string
+		to          []string
+		wantFrom    string
+		wantTargets []string
+		wantErr     bool
+	}{
+		{"targets", "node-a", []string{"node-b", "10.0.0.3"}, "S0", []string{"S1", "S2"}, false},
+		{"unknown template", "node-x", []string{"node-b"}, "", nil, true},
+		{"unknown target", "node-a", []string{"node-x"}, "", nil, true},
+		{"target is the template", "node-a", []string{"node-b", "S0"}, "", nil, true},
+		{"target by another name is the template", "S0", []string{"10.0.0.1"}, "", nil, true},
+		{"target twice", "node-a", []string{"node-b", "S1"}, "", nil, true},
+	}
+
+	for _, tt := range tests {
+		from, targets, err := cloneAgents(agents, tt.from, tt.to)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: cloneAgents() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		var got []string
+		for _, a := range targets {
+			got = append(got, a.AgentInfo.ID.Value)
+		}
+		if from != tt.wantFrom || !reflect.DeepEqual(got, tt.wantTargets) {
+			t.Errorf("%s: cloneAgents() = %s, %v, want %s, %v", tt.name, from, got, tt.wantFrom, tt.wantTargets)
+		}
+	}
+}
+
+func TestAvailableResources(t *testing.T) {
+	allocated := unreserved("cpus", 1.5)
+	allocated.AllocationInfo = &mesos.Resource_AllocationInfo{}
+	reservedAllocated := resource("mem", "r", "p", 1024)
+	agent := master.Response_GetAgents_Agent{AllocatedResources: []mesos.Resource{allocated, reservedAllocated}}
+	state := AgentState{AgentUnreservedResourcesFull: ResourceRole{unreserved("cpus", 4), unreserved("mem", 4096)}}
+
+	want := []string{"cpus:2.500", "mem:4096.000"}
+	if got := describe(availableResources(agent, state)); !reflect.DeepEqual(got, want) {
+		t.Errorf("availableResources() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,135 @@
+package queries
+
+import (
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/minyk/dcos-resources/client"
+	"sort"
+	"strings"
+)
+
+// a task needs at least some of each of these
+var launchResources = []string{"cpus", "mem"}
+
+type FragmentationReport struct {
+	PrefixMesosMasterApiV1 func() string
+	PrefixMesosSlaveApiV0  func(string) string
+	PrefixMesosSlaveApiV1  func(string) string
+}
+
+func NewFragmentationReport() *FragmentationReport {
+	return &FragmentationReport{
+		PrefixMesosMasterApiV1: func() string { return "/mesos/api/v1/" },
+		PrefixMesosSlaveApiV0:  func(agentid string) string { return "/agent/" + agentid },
+		PrefixMesosSlaveApiV1:  func(agentid string) string { return "/agent/" + agentid + "/api/v1" },
+	}
+}
+
+type taskShape struct {
+	agentid string
+	free    map[string]float64
+}
+
+// Fragmentation prints the reservations which no task can use, because the role has none of another resource a
+// task needs on the same agent, reserved or unreserved. It also prints the largest task each role can launch,
+// i.e. the one with the most cpus, then the most memory, on a single agent.
+func (q *FragmentationReport) Fragmentation() error {
+	agents, err := getAgents(q.PrefixMesosMasterApiV1())
+	if err != nil {
+		return err
+	}
+
+	largest := make(map[string]taskShape)
+	seen := make(map[string]bool)
+
+	client.PrintMessage("AgentID\t\tHostname\t\tRole\t\tStranded\t\tMissing")
+	for _, agent := range agents {
+		agentid := agent.AgentInfo.ID.Value
+		state, err := getAgentState(q.PrefixMesosSlaveApiV0(agentid))
+		if err != nil {
+			return err
+		}
+
+		unreserved := freeScalars(agent, state)
+
+		// reservations which are not allocated to a framework
+		reserved := make(map[string]map[string]float64)
+		for role, resources := range state.AgentReservedResourcesFull {
+			reserved[role] = make(map[string]float64)
+			for _, r := range resources {
+				if r.GetType() == mesos.SCALAR {
+					reserved[role][r.GetName()] += r.GetScalar().GetValue()
+				}
+			}
+		}
+		for _, r := range agent.GetAllocatedResources() {
+			role := r.ReservationRole()
+			if r.GetType() == mesos.SCALAR && reserved[role] != nil {
+				reserved[role][r.GetName()] -= r.GetScalar().GetValue()
+			}
+		}
+
+		var roles []string
+		for role := range reserved {
+			roles = append(roles, role)
+		}
+		sort.Strings(roles)
+
+		for _, role := range roles {
+			seen[role] = true
+			free := make(map[string]float64)
+			for name, value := range unreserved {
+				free[name] += value
+			}
+			for name, value := range reserved[role] {
+				free[name] += value
+			}
+
+			var missing []string
+			for _, name := range launchResources {
+				if free[name] <= 0 {
+					missing = append(missing, name)
+				}
+			}
+
+			if len(missing) > 0 {
+				stranded := make(map[string]float64)
+				for name, value := range reserved[role] {
+					if value > 0 {
+						stranded[name] = value
+					}
+				}
+				if len(stranded) > 0 {
+					client.PrintMessage("%s\t\t%s\t\t%s\t\t%s\t\t%s", agentid, agent.AgentInfo.Hostname, role, formatScalars(stranded), strings.Join(missing, ","))
+				}
+				continue
+			}
+
+			best, ok := largest[role]
+			if !ok || free["cpus"] > best.free["cpus"] || (free["cpus"] == best.free["cpus"] && free["mem"] > best.free["mem"]) {
+				largest[role] = taskShape{agentid: agentid, free: free}
+			}
+		}
+	}
+
+	var roles []string
+	for role := range seen {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+
+	client.PrintMessage("Role\t\tAgentID\t\tLargest task")
+	for _, role := range roles {
+		shape, ok := largest[role]
+		if !ok {
+			client.PrintMessage("%s\t\t-\t\t-", role)
+			continue
+		}
+		var values []string
+		for _, name := range append(launchResources, "disk") {
+			values = append(values, name+":"+formatScalar(name, shape.free[name]))
+		}
+		client.PrintMessage("%s\t\t%s\t\t%s", role, shape.agentid, strings.Join(values, ","))
+	}
+
+	return nil
+}
@@ -21,9 +21,14 @@ func NewResourceList() *ResourceList {
 	}
 }
 
-func (q *ResourceList) ListResourcesFromNode(agentid string, role string) error {
+func (q *ResourceList) ListResourcesFromNode(agentid string, role string, includeChildren bool) error {
 
-	resources, err := getResourcesOnRole(q.PrefixMesosSlaveApiV0(agentid), role, "")
+	agentid, err := resolveAgent(q.PrefixMesosMasterApiV1(), agentid)
+	if err != nil {
+		return err
+	}
+
+	resources, err := getResourcesOnRoles(q.PrefixMesosSlaveApiV0(agentid), role, "", includeChildren)
 	if err != nil {
 		return err
 	}
@@ -33,20 +38,18 @@ func (q *ResourceList) ListResourcesFromNode(agentid string, role string) error
 		resource := resources[i]
 		rid, fid := getIDsFromLabels(resource.GetReservation().GetLabels().GetLabels())
 		if resource.GetName() == "disk" {
-			client.PrintMessage("%s\t\t%s\t\t%s\t\t%s\t\t%f\t\t%s\t\t%s\t\t%s", resource.GetRole(), resource.GetReservation().GetPrincipal(), fid, resource.GetName(), resource.GetScalar().GetValue(), rid, resource.GetDisk().GetPersistence().GetID(), resource.GetDisk().GetVolume().GetContainerPath())
-		} else if resource.GetName() == "ports" {
-			client.PrintMessage("%s\t\t%s\t\t%s\t\t%s\t\t%f\t\t%s", resource.GetRole(), resource.GetReservation().GetPrincipal(), fid, resource.GetName(), resource.GetRanges().GoString(), rid)
+			client.PrintMessage("%s\t\t%s\t\t%s\t\t%s\t\t%s\t\t%s\t\t%s\t\t%s", resource.ReservationRole(), resource.GetReservation().GetPrincipal(), fid, resource.GetName(), resourceValue(resource), rid, resource.GetDisk().GetPersistence().GetID(), resource.GetDisk().GetVolume().GetContainerPath())
 		} else {
-			client.PrintMessage("%s\t\t%s\t\t%s\t\t%s\t\t%f\t\t%s", resource.GetRole(), resource.GetReservation().GetPrincipal(), fid, resource.GetName(), resource.GetScalar().GetValue(), rid)
+			client.PrintMessage("%s\t\t%s\t\t%s\t\t%s\t\t%s\t\t%s", resource.ReservationRole(), resource.GetReservation().GetPrincipal(), fid, resource.GetName(), resourceValue(resource), rid)
 		}
 	}
 
-	getResourceOnExecutors(q.PrefixMesosSlaveApiV1(agentid), role)
+	getResourceOnExecutors(q.PrefixMesosSlaveApiV1(agentid), role, includeChildren)
 
 	return nil
 }
 
-func getResourceOnExecutors(urlPath string, role string) ([]agent.Response_GetExecutors_Executor, error) {
+func getResourceOnExecutors(urlPath string, role string, includeChildren bool) ([]agent.Response_GetExecutors_Executor, error) {
 	allExec, err := getExecutors(urlPath)
 	if err != nil {
 		return nil, err
@@ -56,17 +59,16 @@ func getResourceOnExecutors(urlPath string, role string) ([]agent.Response_GetEx
 	for _, exec := range allExec {
 		execInfo := exec.GetExecutorInfo()
 		for _, r := range execInfo.GetResources() {
-			if r.GetAllocationInfo().GetRole() == role && len(r.GetReservations()) > 0 {
+			allocated := r.GetAllocationInfo().GetRole() == role || (includeChildren && isSubrole(r.GetAllocationInfo().GetRole(), role))
+			if allocated && len(r.GetReservations()) > 0 {
 				rid, fid := getIDsFromLabels(r.GetReservations()[0].GetLabels().GetLabels())
 				if r.GetName() == "disk" {
-					client.PrintMessage("%s\t\t%s\t\t%s\t\t%s\t\t%s\t\t%f\t\t%s\t\t%s\t\t%s", execInfo.GetExecutorID(), r.GetRole(), r.GetReservations()[0].GetPrincipal(), fid, r.GetName(), r.GetScalar().GetValue(), rid, r.GetDisk().GetPersistence().GetID(), r.GetDisk().GetVolume().GetContainerPath())
-				} else if r.GetName() == "ports" {
-					client.PrintMessage("%s\t\t%s\t\t%s\t\t%s\t\t%s\t\t%f\t\t%s", execInfo.GetExecutorID(), r.GetRole(), r.GetReservations()[0].GetPrincipal(), fid, r.GetName(), r.GetRanges().GoString(), rid)
+					client.PrintMessage("%s\t\t%s\t\t%s\t\t%s\t\t%s\t\t%s\t\t%s\t\t%s\t\t%s", execInfo.GetExecutorID(), r.GetRole(), r.GetReservations()[0].GetPrincipal(), fid, r.GetName(), resourceValue(r), rid, r.GetDisk().GetPersistence().GetID(), r.GetDisk().GetVolume().GetContainerPath())
 				} else {
-					client.PrintMessage("%s\t\t%s\t\t%s\t\t%s\t\t%s\t\t%f\t\t%s", execInfo.GetExecutorID(), r.GetRole(), r.GetReservations()[0].GetPrincipal(), fid, r.GetName(), r.GetScalar().GetValue(), rid)
+					client.PrintMessage("%s\t\t%s\t\t%s\t\t%s\t\t%s\t\t%s\t\t%s", execInfo.GetExecutorID(), r.GetRole(), r.GetReservations()[0].GetPrincipal(), fid, r.GetName(), resourceValue(r), rid)
 				}
-			} else if r.GetAllocationInfo().GetRole() == role && len(r.GetReservations()) <= 0 {
-				client.PrintMessage("%s\t\t%s\t\t%s\t\t%s\t\t%s\t\t%f\t\t", execInfo.GetExecutorID(), r.GetRole(), "", "", r.GetName(), r.GetScalar().GetValue())
+			} else if allocated && len(r.GetReservations()) <= 0 {
+				client.PrintMessage("%s\t\t%s\t\t%s\t\t%s\t\t%s\t\t%s\t\t", execInfo.GetExecutorID(), r.GetRole(), "", "", r.GetName(), resourceValue(r))
 			}
 		}
 	}
@@ -74,6 +76,27 @@ func getResourceOnExecutors(urlPath string, role string) ([]agent.Response_GetEx
 	return nil, nil
 }
 
+// executorsOnRole returns the IDs of executors which use resources allocated to the role.
+func executorsOnRole(urlPath string, role string) ([]string, error) {
+	allExec, err := getExecutors(urlPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var executors []string
+	for _, exec := range allExec {
+		execInfo := exec.GetExecutorInfo()
+		for _, r := range execInfo.GetResources() {
+			if r.GetAllocationInfo().GetRole() == role {
+				executors = append(executors, execInfo.ExecutorID.Value)
+				break
+			}
+		}
+	}
+
+	return executors, nil
+}
+
 func getExecutors(urlPath string) ([]agent.Response_GetExecutors_Executor, error) {
 	body := agentcalls.GetExecutors()
 	requestContent, err := json.Marshal(body)
@@ -0,0 +1,235 @@
+package queries
+
+import (
+	"fmt"
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/maintenance"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	mastercalls "github.com/mesos/mesos-go/api/v1/lib/master/calls"
+	"github.com/minyk/dcos-resources/client"
+	"strings"
+	"time"
+)
+
+type Maintenance struct {
+	PrefixMesosMasterApiV1 func() string
+	PrefixMesosSlaveApiV0  func(string) string
+	PrefixMesosSlaveApiV1  func(string) string
+}
+
+func NewMaintenance() *Maintenance {
+	return &Maintenance{
+		PrefixMesosMasterApiV1: func() string { return "/mesos/api/v1/" },
+		PrefixMesosSlaveApiV0:  func(agentid string) string { return "/agent/" + agentid },
+		PrefixMesosSlaveApiV1:  func(agentid string) string { return "/agent/" + agentid + "/api/v1" },
+	}
+}
+
+// Schedule adds a maintenance window for the machines to the current maintenance schedule.
+func (q *Maintenance) Schedule(machines []string, start string, duration time.Duration) error {
+	startTime := time.Now()
+	if start != "" {
+		var err error
+		startTime, err = time.Parse(time.RFC3339, start)
+		if err != nil {
+			return err
+		}
+	}
+
+	response, err := callMaster(q.PrefixMesosMasterApiV1(), mastercalls.GetMaintenanceSchedule())
+	if err != nil {
+		return err
+	}
+
+	schedule := response.GetGetMaintenanceSchedule().GetSchedule()
+	schedule.Windows = append(schedule.Windows, maintenance.Window{
+		MachineIDs: parseMachines(machines),
+		Unavailability: mesos.Unavailability{
+			Start:    mesos.TimeInfo{Nanoseconds: startTime.UnixNano()},
+			Duration: &mesos.DurationInfo{Nanoseconds: duration.Nanoseconds()},
+		},
+	})
+
+	_, err = callMaster(q.PrefixMesosMasterApiV1(), mastercalls.UpdateMaintenanceSchedule(schedule))
+	if err != nil {
+		return err
+	}
+
+	client.PrintMessage("Scheduling maintenance is successful.")
+
+	return nil
+}
+
+// Start reports the reservations and volumes on the machines and starts maintenance. If snapshotFile is set, the
+// reservations are saved to it first, so they can be re-created with 'restore' after the machines come back.
+func (q *Maintenance) Start(machines []string, snapshotFile string) error {
+	machineIDs := parseMachines(machines)
+
+	agents, err := getAgents(q.PrefixMesosMasterApiV1())
+	if err != nil {
+		return err
+	}
+
+	var agentIDs []string
+	for _, agent := range filterAgentsOnMachines(agents, machineIDs) {
+		agentIDs = append(agentIDs, agent.AgentInfo.ID.Value)
+	}
+
+	snapshot, err := snapshotAgents(agentIDs, q.PrefixMesosSlaveApiV0)
+	if err != nil {
+		return err
+	}
+
+	client.PrintMessage("AgentID\t\tRole\t\tPrincipal\t\tType\t\tValue\t\tID\t\tPersistentID")
+	for _, agent := range snapshot.Agents {
+		for _, resources := range agent.AgentReservedResourcesFull {
+			for _, r := range resources {
+				rid, _ := getIDsFromLabels(r.GetReservation().GetLabels().GetLabels())
+				client.PrintMessage("%s\t\t%s\t\t%s\t\t%s\t\t%s\t\t%s\t\t%s", agent.AgentID, r.ReservationRole(), r.GetReservation().GetPrincipal(), r.GetName(), resourceValue(r), rid, r.GetDisk().GetPersistence().GetID())
+			}
+		}
+	}
+
+	if snapshotFile != "" {
+		err = writeSnapshot(snapshotFile, snapshot)
+		if err != nil {
+			return err
+		}
+		client.PrintMessage("Saved reservations of %d agents to %s", len(snapshot.Agents), snapshotFile)
+	}
+
+	_, err = callMaster(q.PrefixMesosMasterApiV1(), mastercalls.StartMaintenance(machineIDs...))
+	if err != nil {
+		return err
+	}
+
+	client.PrintMessage("Starting maintenance is successful.")
+
+	return nil
+}
+
+func (q *Maintenance) Stop(machines []string) error {
+	_, err := callMaster(q.PrefixMesosMasterApiV1(), mastercalls.StopMaintenance(parseMachines(machines)...))
+	if err != nil {
+		return err
+	}
+
+	client.PrintMessage("Stopping maintenance is successful.")
+	client.PrintMessage("Run 'restore' with the snapshot of 'maintenance start' to re-create missing reservations.")
+
+	return nil
+}
+
+// Status prints the maintenance windows with the mode of each machine and the number of reservations of the
+// agents on it.
+func (q *Maintenance) Status() error {
+	response, err := callMaster(q.PrefixMesosMasterApiV1(), mastercalls.GetMaintenanceSchedule())
+	if err != nil {
+		return err
+	}
+	schedule := response.GetGetMaintenanceSchedule().GetSchedule()
+
+	response, err = callMaster(q.PrefixMesosMasterApiV1(), mastercalls.GetMaintenanceStatus())
+	if err != nil {
+		return err
+	}
+	status := response.GetGetMaintenanceStatus().GetStatus()
+
+	modes := make(map[string]string)
+	for _, machine := range status.GetDrainingMachines() {
+		modes[machineName(machine.ID)] = "DRAINING"
+	}
+	for _, machine := range status.GetDownMachines() {
+		modes[machineName(machine)] = "DOWN"
+	}
+
+	agents, err := getAgents(q.PrefixMesosMasterApiV1())
+	if err != nil {
+		return err
+	}
+
+	// agents under maintenance are likely to be down, so they are reported instead of failing the status
+	var unreachable []string
+	client.PrintMessage("Machine\t\tMode\t\tStart\t\tDuration\t\tReservations")
+	for _, window := range schedule.GetWindows() {
+		unavailability := window.GetUnavailability()
+		start := time.Unix(0, unavailability.Start.Nanoseconds).UTC().Format(time.RFC3339)
+		duration := time.Duration(unavailability.GetDuration().GetNanoseconds())
+
+		for _, machine := range window.GetMachineIDs() {
+			mode, ok := modes[machineName(machine)]
+			if !ok {
+				mode = "SCHEDULED"
+			}
+
+			reservations := "-"
+			onMachine := filterAgentsOnMachines(agents, []mesos.MachineID{machine})
+			if len(onMachine) > 0 {
+				count := 0
+				for _, agent := range onMachine {
+					resourcesFull, err := listResources(q.PrefixMesosSlaveApiV0(agent.AgentInfo.ID.Value))
+					if err != nil {
+						unreachable = append(unreachable, agent.AgentInfo.ID.Value)
+						count = -1
+						break
+					}
+					for _, resources := range resourcesFull {
+						count += len(resources)
+					}
+				}
+				reservations = fmt.Sprintf("%d", count)
+				if count < 0 {
+					reservations = "unreachable"
+				}
+			}
+
+			client.PrintMessage("%s\t\t%s\t\t%s\t\t%s\t\t%s", machineName(machine), mode, start, duration, reservations)
+		}
+	}
+
+	if len(unreachable) > 0 {
+		client.PrintMessage("%d agents could not be reached: %s", len(unreachable), strings.Join(unreachable, ", "))
+	}
+
+	return nil
+}
+
+// parseMachines parses machines given as hostname or hostname=ip.
+func parseMachines(machines []string) []mesos.MachineID {
+	var machineIDs []mesos.MachineID
+	for _, machine := range machines {
+		parts := strings.SplitN(machine, "=", 2)
+		hostname := parts[0]
+		machineID := mesos.MachineID{Hostname: &hostname}
+		if len(parts) == 2 {
+			ip := parts[1]
+			machineID.IP = &ip
+		}
+		machineIDs = append(machineIDs, machineID)
+	}
+	return machineIDs
+}
+
+func machineName(machine mesos.MachineID) string {
+	if machine.GetIP() == "" {
+		return machine.GetHostname()
+	}
+	return machine.GetHostname() + "=" + machine.GetIP()
+}
+
+func filterAgentsOnMachines(agents []master.Response_GetAgents_Agent, machines []mesos.MachineID) []master.Response_GetAgents_Agent {
+	var onMachines []master.Response_GetAgents_Agent
+	for _, agent := range agents {
+		for _, machine := range machines {
+			if machine.Hostname != nil && machine.GetHostname() != agent.AgentInfo.Hostname {
+				continue
+			}
+			if machine.IP != nil && machine.GetIP() != agentIP(agent) {
+				continue
+			}
+			onMachines = append(onMachines, agent)
+			break
+		}
+	}
+	return onMachines
+}
@@ -0,0 +1,49 @@
+package queries
+
+import (
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/maintenance"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	"testing"
+)
+
+func TestStatusWithUnreachableAgents(t *testing.T) {
+	machines := parseMachines([]string{"node-a=10.0.0.1", "node-b"})
+	agents := []master.Response_GetAgents_Agent{
+		testAgent("S0", "node-a", "10.0.0.1", "", nil),
+		testAgent("S1", "node-b", "10.0.0.2", "", nil),
+	}
+	responses := map[master.Call_Type]master.Response{
+		master.Call_GET_AGENTS: agentsResponse(agents...),
+		master.Call_GET_MAINTENANCE_SCHEDULE: {
+			Type: master.Response_GET_MAINTENANCE_SCHEDULE,
+			GetMaintenanceSchedule: &master.Response_GetMaintenanceSchedule{Schedule: maintenance.Schedule{Windows: []maintenance.Window{{
+				MachineIDs:     machines,
+				Unavailability: mesos.Unavailability{Start: mesos.TimeInfo{Nanoseconds: 0}},
+			}}}},
+		},
+		master.Call_GET_MAINTENANCE_STATUS: {
+			Type: master.Response_GET_MAINTENANCE_STATUS,
+			GetMaintenanceStatus: &master.Response_GetMaintenanceStatus{Status: maintenance.ClusterStatus{
+				DownMachines: machines[:1],
+			}},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		states map[string]AgentState
+	}{
+		{"all agents reachable", map[string]AgentState{"S0": {}, "S1": {}}},
+		{"down agent unreachable", map[string]AgentState{"S1": {}}},
+		{"no agent reachable", map[string]AgentState{}},
+	}
+
+	for _, tt := range tests {
+		stop := testCluster(responses, tt.states)
+		if err := NewMaintenance().Status(); err != nil {
+			t.Errorf("%s: Status() error = %v", tt.name, err)
+		}
+		stop()
+	}
+}
@@ -0,0 +1,177 @@
+package queries
+
+import (
+	"errors"
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/minyk/dcos-resources/client"
+	"strings"
+	"time"
+)
+
+type MigrateRole struct {
+	PrefixMesosMasterApiV1 func() string
+	PrefixMesosSlaveApiV0  func(string) string
+	PrefixMesosSlaveApiV1  func(string) string
+}
+
+func NewMigrateRole() *MigrateRole {
+	return &MigrateRole{
+		PrefixMesosMasterApiV1: func() string { return "/mesos/api/v1/" },
+		PrefixMesosSlaveApiV0:  func(agentid string) string { return "/agent/" + agentid },
+		PrefixMesosSlaveApiV1:  func(agentid string) string { return "/agent/" + agentid + "/api/v1" },
+	}
+}
+
+// MigrateRole moves the reservations of role from to role to, on one agent or on all agents if agentid is empty.
+// If to is a child of from, the reservations are refined. Otherwise unused reservations are unreserved and reserved
+// again for the new role. Persistent volumes are reported and left untouched.
+func (q *MigrateRole) MigrateRole(from string, to string, agentid string, wait time.Duration) error {
+	var agents []string
+	var err error
+	if agentid == "" {
+		agents, err = getAgentList(q.PrefixMesosMasterApiV1())
+	} else {
+		agents, err = resolveAgents(q.PrefixMesosMasterApiV1(), []string{agentid})
+	}
+	if err != nil {
+		return err
+	}
+
+	refine := strings.HasPrefix(to, from+"/")
+	blocked := 0
+
+	for _, agent := range agents {
+		resourcesFull, err := listResources(q.PrefixMesosSlaveApiV0(agent))
+		if err != nil {
+			return err
+		}
+
+		resources := resourcesFull[from]
+		if len(resources) == 0 {
+			continue
+		}
+
+		// refining leaves the reservations in place, only unreserving them would take resources from executors
+		if !refine {
+			executors, err := executorsOnRole(q.PrefixMesosSlaveApiV1(agent), from)
+			if err != nil {
+				return err
+			}
+			if len(executors) > 0 {
+				client.PrintMessage("Blocked on %s: reservations are in use by executors: %s", agent, strings.Join(executors, ", "))
+				blocked++
+				continue
+			}
+		}
+
+		var added, removed []mesos.Resource
+		for _, r := range resources {
+			rid, _ := getIDsFromLabels(r.GetReservation().GetLabels().GetLabels())
+
+			if r.GetDisk().GetPersistence().GetID() != "" {
+				client.PrintMessage("Blocked on %s: persistent volume %s (%s)", agent, r.GetDisk().GetPersistence().GetID(), rid)
+				blocked++
+				continue
+			}
+
+			if refine {
+				err = reserveResources(q.PrefixMesosMasterApiV1(), agent, refineReservation(r, to))
+				added = append(added, refineReservation(r, to))
+			} else {
+				err = q.reserveOnRole(agent, r, to)
+				added = append(added, withRole(r, to))
+			}
+			if err != nil {
+				return err
+			}
+			removed = append(removed, r)
+			client.PrintMessage("Migrated %s %s on %s from %s to %s: %s", r.GetName(), resourceValue(r), agent, from, to, rid)
+		}
+
+		if len(removed) > 0 {
+			err = waitForChange(q.PrefixMesosSlaveApiV0(agent), agent, wait, resourcesFull, added, removed)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if blocked > 0 {
+		return errors.New("some reservations could not be migrated")
+	}
+
+	client.PrintMessage("Migration is successful.")
+
+	return nil
+}
+
+// reserveOnRole unreserves a reservation and reserves it again for another role. The original reservation is
+// restored if the new reservation fails.
+func (q *MigrateRole) reserveOnRole(agentid string, r mesos.Resource, role string) error {
+	err := unreserveResources(q.PrefixMesosMasterApiV1(), agentid, r)
+	if err != nil {
+		return err
+	}
+
+	err = reserveResources(q.PrefixMesosMasterApiV1(), agentid, withRole(r, role))
+	if err != nil {
+		rollbackErr := reserveResources(q.PrefixMesosMasterApiV1(), agentid, withoutVolume(r))
+		if rollbackErr != nil {
+			return errors.New(err.Error() + "\nrestoring the original reservation failed: " + rollbackErr.Error())
+		}
+		return err
+	}
+
+	return nil
+}
+
+// reservationStack returns the reservations of a resource in the post reservation refinement format.
+func reservationStack(r mesos.Resource) []mesos.Resource_ReservationInfo {
+	if len(r.Reservations) > 0 {
+		return append([]mesos.Resource_ReservationInfo{}, r.Reservations...)
+	}
+
+	role := r.GetRole()
+	return []mesos.Resource_ReservationInfo{{
+		Type:      mesos.Resource_ReservationInfo_DYNAMIC.Enum(),
+		Role:      &role,
+		Principal: r.GetReservation().Principal,
+		Labels:    r.GetReservation().GetLabels(),
+	}}
+}
+
+// refineReservation returns a reservation which refines the reservation of r to a child role.
+func refineReservation(r mesos.Resource, role string) mesos.Resource {
+	r = withoutVolume(r)
+	stack := reservationStack(r)
+	last := stack[len(stack)-1]
+
+	r.Role = nil
+	r.Reservation = nil
+	r.Reservations = append(stack, mesos.Resource_ReservationInfo{
+		Type:      mesos.Resource_ReservationInfo_DYNAMIC.Enum(),
+		Role:      &role,
+		Principal: last.Principal,
+		Labels:    last.Labels,
+	})
+
+	return r
+}
+
+// withRole returns the reservation of r for another role, keeping its principal and labels. Refined reservations
+// only exist in the post reservation refinement format, so the pre refinement role is set for unrefined ones only.
+func withRole(r mesos.Resource, role string) mesos.Resource {
+	r = withoutVolume(r)
+	stack := reservationStack(r)
+	stack[len(stack)-1].Role = &role
+
+	r.Reservations = stack
+	if len(stack) > 1 {
+		r.Role = nil
+		r.Reservation = nil
+		return r
+	}
+	r.Role = &role
+
+	return r
+}
@@ -0,0 +1,104 @@
+package queries
+
+import (
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"reflect"
+	"testing"
+)
+
+// stackRoles returns the roles of the reservation stack of r, bottom first.
+func stackRoles(r mesos.Resource) []string {
+	var roles []string
+	for _, reservation := range r.Reservations {
+		roles = append(roles, reservation.GetRole())
+	}
+	return roles
+}
+
+// refined returns r refined from its role to the child roles, in the post reservation refinement format.
+func refined(r mesos.Resource, roles ...string) mesos.Resource {
+	for _, role := range roles {
+		r = refineReservation(r, role)
+	}
+	return r
+}
+
+func TestRefineReservation(t *testing.T) {
+	tests := []struct {
+		name      string
+		r         mesos.Resource
+		role      string
+		wantRoles []string
+	}{
+		{"legacy format", resource("cpus", "eng", "p", 1), "eng/backend", []string{"eng", "eng/backend"}},
+		{"labeled", labeled(resource("cpus", "eng", "p", 1), "rid-1"), "eng/backend", []string{"eng", "eng/backend"}},
+		{"refined", refined(resource("cpus", "eng", "p", 1), "eng/backend"), "eng/backend/db", []string{"eng", "eng/backend", "eng/backend/db"}},
+		{"volume", labeled(volume("eng", 100, "v1"), "rid-2"), "eng/backend", []string{"eng", "eng/backend"}},
+	}
+
+	for _, tt := range tests {
+		original := stackRoles(tt.r)
+		got := refineReservation(tt.r, tt.role)
+
+		if roles := stackRoles(got); !reflect.DeepEqual(roles, tt.wantRoles) {
+			t.Errorf("%s: refineReservation() stack = %v, want %v", tt.name, roles, tt.wantRoles)
+		}
+		if got.ReservationRole() != tt.role || got.Role != nil || got.Reservation != nil {
+			t.Errorf("%s: refineReservation() role = %s, legacy role %v, want only the stack for %s", tt.name, got.ReservationRole(), got.GetRole(), tt.role)
+		}
+		if got.GetDisk().GetPersistence() != nil {
+			t.Errorf("%s: refineReservation() kept the persistent volume", tt.name)
+		}
+		if resourceValue(got) != resourceValue(tt.r) {
+			t.Errorf("%s: refineReservation() = %s, want %s", tt.name, resourceValue(got), resourceValue(tt.r))
+		}
+
+		top := got.Reservations[len(got.Reservations)-1]
+		below := got.Reservations[len(got.Reservations)-2]
+		if top.GetPrincipal() != below.GetPrincipal() || !reflect.DeepEqual(top.GetLabels(), below.GetLabels()) {
+			t.Errorf("%s: refineReservation() principal %s labels %v, want %s %v", tt.name, top.GetPrincipal(), top.GetLabels(), below.GetPrincipal(), below.GetLabels())
+		}
+		if roles := stackRoles(tt.r); !reflect.DeepEqual(roles, original) {
+			t.Errorf("%s: refineReservation() changed the stack of the original to %v", tt.name, roles)
+		}
+	}
+}
+
+func TestWithRole(t *testing.T) {
+	// the state of an agent reports the role of a refined reservation next to its reservation stack
+	mixed := refined(labeled(resource("cpus", "eng", "p", 1), "rid-1"), "eng/backend")
+	role := "eng/backend"
+	mixed.Role = &role
+	mixed.Reservation = &mesos.Resource_ReservationInfo{Principal: mixed.Reservations[0].Principal}
+
+	tests := []struct {
+		name       string
+		r          mesos.Resource
+		role       string
+		wantRoles  []string
+		wantLegacy bool
+	}{
+		{"legacy format", resource("cpus", "eng", "p", 1), "ops", []string{"ops"}, true},
+		{"labeled", labeled(resource("cpus", "eng", "p", 1), "rid-1"), "ops", []string{"ops"}, true},
+		{"refined", refined(resource("cpus", "eng", "p", 1), "eng/backend"), "eng/frontend", []string{"eng", "eng/frontend"}, false},
+		{"refined in both formats", mixed, "eng/frontend", []string{"eng", "eng/frontend"}, false},
+	}
+
+	for _, tt := range tests {
+		original := tt.r.ReservationRole()
+		got := withRole(tt.r, tt.role)
+
+		if roles := stackRoles(got); !reflect.DeepEqual(roles, tt.wantRoles) || got.ReservationRole() != tt.role {
+			t.Errorf("%s: withRole() stack = %v, want %v", tt.name, roles, tt.wantRoles)
+		}
+		if legacy := got.Role != nil || got.Reservation != nil; legacy != tt.wantLegacy {
+			t.Errorf("%s: withRole() role = %v, reservation = %v, want the pre refinement format %v", tt.name, got.Role, got.Reservation, tt.wantLegacy)
+		}
+		if tt.wantLegacy && got.GetRole() != tt.role {
+			t.Errorf("%s: withRole() role = %s, want %s", tt.name, got.GetRole(), tt.role)
+		}
+		if tt.r.ReservationRole() != original {
+			t.Errorf("%s: withRole() changed the role of the original to %s", tt.name, tt.r.ReservationRole())
+		}
+	}
+}
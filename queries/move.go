@@ -0,0 +1,109 @@
+package queries
+
+import (
+	"fmt"
+	"github.com/minyk/dcos-resources/client"
+	"strings"
+	"time"
+)
+
+type MoveResources struct {
+	PrefixMesosMasterApiV1 func() string
+	PrefixMesosSlaveApiV0  func(string) string
+	PrefixMesosSlaveApiV1  func(string) string
+}
+
+func NewMoveResources() *MoveResources {
+	return &MoveResources{
+		PrefixMesosMasterApiV1: func() string { return "/mesos/api/v1/" },
+		PrefixMesosSlaveApiV0:  func(agentid string) string { return "/agent/" + agentid },
+		PrefixMesosSlaveApiV1:  func(agentid string) string { return "/agent/" + agentid + "/api/v1" },
+	}
+}
+
+// Move reserves the unused reservations of a role on the target agent, then unreserves them on the source agent.
+// The reservation on the target agent is rolled back if unreserving on the source agent fails.
+func (q *MoveResources) Move(from string, to string, role string, principal string, wait time.Duration) error {
+	agents, err := getAgents(q.PrefixMesosMasterApiV1())
+	if err != nil {
+		return err
+	}
+	from, err = matchAgent(agents, from)
+	if err != nil {
+		return err
+	}
+	target, err := findAgent(agents, to)
+	if err != nil {
+		return err
+	}
+	to = target.AgentInfo.ID.Value
+
+	resources, err := getResourcesOnRole(q.PrefixMesosSlaveApiV0(from), role, principal)
+	if err != nil {
+		return err
+	}
+
+	var volumes []string
+	for _, r := range resources {
+		if r.GetDisk().GetPersistence().GetID() != "" {
+			volumes = append(volumes, r.GetDisk().GetPersistence().GetID())
+		}
+	}
+	if len(volumes) > 0 {
+		return fmt.Errorf("persistent volumes of %s can not be moved: %s", role, strings.Join(volumes, ", "))
+	}
+
+	executors, err := executorsOnRole(q.PrefixMesosSlaveApiV1(from), role)
+	if err != nil {
+		return err
+	}
+	if len(executors) > 0 {
+		return fmt.Errorf("reservations of %s are in use by executors: %s", role, strings.Join(executors, ", "))
+	}
+
+	var reservations ResourceRole
+	for _, r := range resources {
+		reservations = append(reservations, withoutVolume(r))
+	}
+
+	state, err := getAgentState(q.PrefixMesosSlaveApiV0(to))
+	if err != nil {
+		return err
+	}
+
+	err = checkCapacity(to, availableResources(target, state), reservations...)
+	if err != nil {
+		return err
+	}
+
+	fromBefore, err := reservationsBefore(q.PrefixMesosSlaveApiV0(from), wait)
+	if err != nil {
+		return err
+	}
+
+	err = reserveResources(q.PrefixMesosMasterApiV1(), to, reservations...)
+	if err != nil {
+		return err
+	}
+	client.PrintMessage("Reserved %d resources for %s on %s", len(reservations), role, to)
+
+	err = unreserveResources(q.PrefixMesosMasterApiV1(), from, resources...)
+	if err != nil {
+		client.PrintMessage("Unreserving on %s failed, rolling back reservation on %s", from, to)
+		rollbackErr := unreserveResources(q.PrefixMesosMasterApiV1(), to, reservations...)
+		if rollbackErr != nil {
+			return fmt.Errorf("%s\nrollback on %s failed: %s", err, to, rollbackErr)
+		}
+		return err
+	}
+	client.PrintMessage("Unreserved %d resources for %s on %s", len(resources), role, from)
+
+	client.PrintMessage("Move is successful.")
+
+	err = waitForChange(q.PrefixMesosSlaveApiV0(to), to, wait, state.AgentReservedResourcesFull, reservations, nil)
+	if err != nil {
+		return err
+	}
+
+	return waitForChange(q.PrefixMesosSlaveApiV0(from), from, wait, fromBefore, nil, resources)
+}
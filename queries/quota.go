@@ -0,0 +1,229 @@
+package queries
+
+import (
+	"fmt"
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	mastercalls "github.com/mesos/mesos-go/api/v1/lib/master/calls"
+	"github.com/mesos/mesos-go/api/v1/lib/quota"
+	"github.com/minyk/dcos-resources/client"
+	"sort"
+	"strings"
+)
+
+type RoleQuota struct {
+	PrefixMesosMasterApiV1 func() string
+	PrefixMesosSlaveApiV0  func(string) string
+	PrefixMesosSlaveApiV1  func(string) string
+}
+
+func NewRoleQuota() *RoleQuota {
+	return &RoleQuota{
+		PrefixMesosMasterApiV1: func() string { return "/mesos/api/v1/" },
+		PrefixMesosSlaveApiV0:  func(agentid string) string { return "/agent/" + agentid },
+		PrefixMesosSlaveApiV1:  func(agentid string) string { return "/agent/" + agentid + "/api/v1" },
+	}
+}
+
+// GetQuota prints the quota of a role, or of all roles if role is empty, next to the resources reserved for it.
+func (q *RoleQuota) GetQuota(role string) error {
+	configs, err := getQuotaConfigs(q.PrefixMesosMasterApiV1())
+	if err != nil {
+		return err
+	}
+
+	reserved, err := getAgentReservedTotals(q.PrefixMesosMasterApiV1())
+	if err != nil {
+		return err
+	}
+
+	var roles []string
+	for r := range configs {
+		roles = append(roles, r)
+	}
+	for r := range reserved {
+		if _, ok := configs[r]; !ok {
+			roles = append(roles, r)
+		}
+	}
+	sort.Strings(roles)
+
+	client.PrintMessage("Role\t\tResource\t\tGuarantee\t\tLimit\t\tReserved")
+	for _, r := range roles {
+		if role != "" && r != role {
+			continue
+		}
+		config := configs[r]
+
+		names := make(map[string]bool)
+		for name := range config.Guarantees {
+			names[name] = true
+		}
+		for name := range config.Limits {
+			names[name] = true
+		}
+		for name := range reserved[r] {
+			names[name] = true
+		}
+		var sorted []string
+		for name := range names {
+			sorted = append(sorted, name)
+		}
+		sort.Strings(sorted)
+
+		for _, name := range sorted {
+			client.PrintMessage("%s\t\t%s\t\t%s\t\t%s\t\t%s", r, name, quotaValue(config.Guarantees, name), quotaValue(config.Limits, name), formatScalar(name, reserved[r][name]))
+		}
+	}
+
+	return nil
+}
+
+// SetQuota updates the guarantees and limits of a role. If legacy is set, SET_QUOTA is used instead of
+// UPDATE_QUOTA for masters older than Mesos 1.9, which only support guarantees.
+func (q *RoleQuota) SetQuota(role string, guarantees map[string]float64, limits map[string]float64, force bool, legacy bool) error {
+	var body *master.Call
+	if legacy {
+		var guarantee []mesos.Resource
+		for name, value := range guarantees {
+			guarantee = append(guarantee, mesos.Resource{
+				Type:   mesos.SCALAR.Enum(),
+				Name:   name,
+				Scalar: &mesos.Value_Scalar{Value: value},
+			})
+		}
+		body = mastercalls.SetQuota(quota.QuotaRequest{Force: &force, Role: &role, Guarantee: guarantee})
+	} else {
+		config := quota.QuotaConfig{
+			Role:       role,
+			Guarantees: make(map[string]mesos.Value_Scalar),
+			Limits:     make(map[string]mesos.Value_Scalar),
+		}
+		for name, value := range guarantees {
+			config.Guarantees[name] = mesos.Value_Scalar{Value: value}
+		}
+		for name, value := range limits {
+			config.Limits[name] = mesos.Value_Scalar{Value: value}
+		}
+		body = &master.Call{
+			Type: master.Call_UPDATE_QUOTA,
+			UpdateQuota: &master.Call_UpdateQuota{
+				Force:        &force,
+				QuotaConfigs: []quota.QuotaConfig{config},
+			},
+		}
+	}
+
+	_, err := callMaster(q.PrefixMesosMasterApiV1(), body)
+	if err != nil {
+		return err
+	}
+
+	client.PrintMessage("Setting quota is successful.")
+
+	return nil
+}
+
+func (q *RoleQuota) RemoveQuota(role string) error {
+	_, err := callMaster(q.PrefixMesosMasterApiV1(), mastercalls.RemoveQuota(role))
+	if err != nil {
+		return err
+	}
+
+	client.PrintMessage("Removing quota is successful.")
+
+	return nil
+}
+
+// getQuotaConfigs returns the quota of all roles. Quota set by masters older than Mesos 1.9 is converted from
+// the legacy QuotaInfo.
+func getQuotaConfigs(masterUrl string) (map[string]quota.QuotaConfig, error) {
+	response, err := callMaster(masterUrl, mastercalls.GetQuota())
+	if err != nil {
+		return nil, err
+	}
+
+	status := response.GetGetQuota().GetStatus()
+	configs := make(map[string]quota.QuotaConfig)
+	for _, config := range status.GetConfigs() {
+		configs[config.Role] = config
+	}
+
+	for _, info := range status.GetInfos() {
+		if _, ok := configs[info.GetRole()]; ok {
+			continue
+		}
+		config := quota.QuotaConfig{Role: info.GetRole(), Guarantees: make(map[string]mesos.Value_Scalar)}
+		for _, r := range info.GetGuarantee() {
+			config.Guarantees[r.GetName()] = mesos.Value_Scalar{Value: r.GetScalar().GetValue()}
+		}
+		configs[info.GetRole()] = config
+	}
+
+	return configs, nil
+}
+
+// checkQuota returns the quota guarantees and limits which reserving the resources for role would exceed. The
+// reservations of children count towards the quota of their ancestors.
+func checkQuota(masterUrl string, role string, resources ...mesos.Resource) ([]string, error) {
+	configs, err := getQuotaConfigs(masterUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	var ancestors []string
+	parts := strings.Split(role, "/")
+	for i := range parts {
+		ancestor := strings.Join(parts[:i+1], "/")
+		if _, ok := configs[ancestor]; ok {
+			ancestors = append(ancestors, ancestor)
+		}
+	}
+	if len(ancestors) == 0 {
+		return nil, nil
+	}
+
+	totals, err := getAgentReservedTotals(masterUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []string
+	for _, ancestor := range ancestors {
+		config := configs[ancestor]
+
+		reserved := make(map[string]float64)
+		for r, scalars := range totals {
+			if isSubrole(r, ancestor) {
+				for name, value := range scalars {
+					reserved[name] += value
+				}
+			}
+		}
+		for _, r := range resources {
+			if r.GetType() == mesos.SCALAR {
+				reserved[r.GetName()] += r.GetScalar().GetValue()
+			}
+		}
+
+		for name, value := range reserved {
+			if guarantee, ok := config.Guarantees[name]; ok && value > guarantee.GetValue() {
+				violations = append(violations, fmt.Sprintf("%s %s reserved for %s exceeds its guarantee of %s", formatScalar(name, value), name, ancestor, formatScalar(name, guarantee.GetValue())))
+			}
+			if limit, ok := config.Limits[name]; ok && value > limit.GetValue() {
+				violations = append(violations, fmt.Sprintf("%s %s reserved for %s exceeds its limit of %s", formatScalar(name, value), name, ancestor, formatScalar(name, limit.GetValue())))
+			}
+		}
+	}
+	sort.Strings(violations)
+
+	return violations, nil
+}
+
+func quotaValue(values map[string]mesos.Value_Scalar, name string) string {
+	value, ok := values[name]
+	if !ok {
+		return "-"
+	}
+	return formatScalar(name, value.GetValue())
+}
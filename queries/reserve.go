@@ -1,10 +1,12 @@
 package queries
 
 import (
-	"encoding/json"
+	"errors"
 	"github.com/mesos/mesos-go/api/v1/lib"
-	mastercalls "github.com/mesos/mesos-go/api/v1/lib/master/calls"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
 	"github.com/minyk/dcos-resources/client"
+	"strings"
+	"time"
 )
 
 type ReserveResources struct {
@@ -21,26 +23,225 @@ func NewResources() *ReserveResources {
 	}
 }
 
-func (q *ReserveResources) ReserveResource(agentid string, role string, principal string, cpus float64, mem float64) error {
+// ReserveResource reserves resources on an agent. If resourceID is given the resources are labeled with it, and
+// if it is given or idempotent is set only the part which is not reserved for role and principal yet is reserved.
+func (q *ReserveResources) ReserveResource(agentid string, role string, principal string, cpus Amount, mem Amount, specs []string, resourceID string, idempotent bool, bestEffort bool, enforceQuota bool, wait time.Duration) error {
+	agent, err := getAgent(q.PrefixMesosMasterApiV1(), agentid)
+	if err != nil {
+		return err
+	}
+	agentid = agent.AgentInfo.ID.Value
 
-	var resources []mesos.Resource
-	resources = append(resources, resource("cpu", role, principal, cpus))
-	resources = append(resources, resource("mem", role, principal, mem))
+	resources, err := agentReservation(agent, role, principal, cpus, mem, specs)
+	if err != nil {
+		return err
+	}
 
-	body := mastercalls.ReserveResources(mesos.AgentID{Value: agentid}, resources...)
+	if resourceID != "" {
+		labelResources(resourceID, resources)
+	}
 
-	requestContent, err := json.Marshal(body)
+	err = validateResources(agent, !bestEffort, resources...)
 	if err != nil {
 		return err
 	}
 
-	_, err = client.HTTPServicePostJSON(q.PrefixMesosMasterApiV1(), requestContent)
+	state, err := getAgentState(q.PrefixMesosSlaveApiV0(agentid))
+	if err != nil {
+		return err
+	}
+
+	if resourceID != "" || idempotent {
+		var reserved []string
+		resources, reserved = missingResources(existingReservation(state, role, principal, resourceID), resources...)
+		for _, r := range reserved {
+			client.PrintMessage("Already reserved: %s", r)
+		}
+		if len(resources) == 0 {
+			client.PrintMessage("Resources are already reserved.")
+			return nil
+		}
+	}
+
+	available := availableResources(agent, state)
+
+	if bestEffort {
+		var cuts []string
+		resources, cuts = fitResources(available, resources...)
+		for _, cut := range cuts {
+			client.PrintMessage("Best effort: %s", cut)
+		}
+		if len(resources) == 0 {
+			return errors.New("nothing is available to reserve on agent " + agentid)
+		}
+	} else {
+		err = checkCapacity(agentid, available, resources...)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = q.checkQuota(role, enforceQuota, resources...)
+	if err != nil {
+		return err
+	}
+
+	err = reserveResources(q.PrefixMesosMasterApiV1(), agentid, resources...)
 	if err != nil {
 		return err
 	} else {
 		client.PrintMessage("Reservation is successful.")
 	}
 
+	return waitForChange(q.PrefixMesosSlaveApiV0(agentid), agentid, wait, state.AgentReservedResourcesFull, resources, nil)
+}
+
+// ReserveAllRemaining reserves all unreserved resources of an agent which are not allocated to a framework for
+// role, or only those with the given names. Every MOUNT disk and port range is reserved as it is.
+func (q *ReserveResources) ReserveAllRemaining(agentid string, role string, principal string, types []string, enforceQuota bool, wait time.Duration) error {
+	agent, err := getAgent(q.PrefixMesosMasterApiV1(), agentid)
+	if err != nil {
+		return err
+	}
+	agentid = agent.AgentInfo.ID.Value
+
+	state, err := getAgentState(q.PrefixMesosSlaveApiV0(agentid))
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]bool)
+	for _, name := range types {
+		names[name] = true
+	}
+
+	var resources []mesos.Resource
+	for _, r := range availableResources(agent, state) {
+		if len(names) > 0 && !names[r.GetName()] {
+			continue
+		}
+		if r.GetType() == mesos.SCALAR && r.GetScalar().GetValue() <= 0 ||
+			r.GetType() == mesos.RANGES && len(r.GetRanges().GetRange()) == 0 ||
+			r.GetType() == mesos.SET && len(r.GetSet().GetItem()) == 0 {
+			continue
+		}
+
+		r.Role = &role
+		r.Reservation = &mesos.Resource_ReservationInfo{Principal: &principal}
+		resources = append(resources, r)
+	}
+	if len(resources) == 0 {
+		return errors.New("nothing is unreserved on agent " + agentid)
+	}
+
+	client.PrintMessage("Type\t\tValue\t\tSource")
+	for _, r := range resources {
+		client.PrintMessage("%s\t\t%s\t\t%s", r.GetName(), resourceValue(r), diskSource(r))
+	}
+
+	err = q.checkQuota(role, enforceQuota, resources...)
+	if err != nil {
+		return err
+	}
+
+	err = reserveResources(q.PrefixMesosMasterApiV1(), agentid, resources...)
+	if err != nil {
+		return err
+	}
+
+	client.PrintMessage("Reservation is successful.")
+
+	return waitForChange(q.PrefixMesosSlaveApiV0(agentid), agentid, wait, state.AgentReservedResourcesFull, resources, nil)
+}
+
+// diskSource describes the source of a disk resource, e.g. MOUNT:/dcos/volume0.
+func diskSource(r mesos.Resource) string {
+	source := r.GetDisk().GetSource()
+	if source == nil {
+		return ""
+	}
+	switch source.GetType() {
+	case mesos.Resource_DiskInfo_Source_MOUNT:
+		return "MOUNT:" + source.GetMount().GetRoot()
+	case mesos.Resource_DiskInfo_Source_PATH:
+		return "PATH:" + source.GetPath().GetRoot()
+	default:
+		return source.GetType().String()
+	}
+}
+
+// agentReservation returns the resources to reserve on an agent. Percentages of cpus and mem are taken of the
+// resources the agent advertises, and the computed amounts are printed.
+func agentReservation(agent master.Response_GetAgents_Agent, role string, principal string, cpus Amount, mem Amount, specs []string) ([]mesos.Resource, error) {
+	advertised := ResourceRole(agent.AgentInfo.GetResources())
+	cpusValue := cpus.of(scalarTotal(advertised, "cpus"))
+	memValue := mem.of(scalarTotal(advertised, "mem"))
+
+	if cpus.Percent > 0 || mem.Percent > 0 {
+		amounts := make(map[string]float64)
+		if cpus.IsSet() {
+			amounts["cpus"] = cpusValue
+		}
+		if mem.IsSet() {
+			amounts["mem"] = memValue
+		}
+		client.PrintMessage("Reserving %s on %s", formatScalars(amounts), agent.AgentInfo.Hostname)
+	}
+
+	return reservation(role, principal, cpusValue, memValue, specs)
+}
+
+// existingReservation returns the resources reserved for role and principal in the state of an agent, only those
+// labeled with resourceID if it is given.
+func existingReservation(state AgentState, role string, principal string, resourceID string) ResourceRole {
+	var existing ResourceRole
+	for _, r := range state.AgentReservedResourcesFull[role] {
+		if r.GetReservation().GetPrincipal() != principal {
+			continue
+		}
+		if rid, _ := getIDsFromLabels(r.GetReservation().GetLabels().GetLabels()); resourceID != "" && rid != resourceID {
+			continue
+		}
+		existing = append(existing, r)
+	}
+	return existing
+}
+
+// labelResources labels the reservation of each resource with resource_id, the label frameworks identify their
+// reservations by.
+func labelResources(resourceID string, resources []mesos.Resource) {
+	for i := range resources {
+		reservation := *resources[i].GetReservation()
+		reservation.Labels = &mesos.Labels{Labels: []mesos.Label{{Key: "resource_id", Value: &resourceID}}}
+		resources[i].Reservation = &reservation
+	}
+}
+
+// checkQuota checks the quota of role against the resources with warnOrEnforceQuota.
+func (q *ReserveResources) checkQuota(role string, enforceQuota bool, resources ...mesos.Resource) error {
+	return warnOrEnforceQuota(q.PrefixMesosMasterApiV1(), role, enforceQuota, resources...)
+}
+
+// warnOrEnforceQuota prints the quota which reserving the resources for role would exceed, or refuses the
+// reservation if enforceQuota is set. If the quota can not be checked, only enforceQuota refuses the reservation.
+func warnOrEnforceQuota(masterUrl string, role string, enforceQuota bool, resources ...mesos.Resource) error {
+	violations, err := checkQuota(masterUrl, role, resources...)
+	if err != nil {
+		if enforceQuota {
+			return err
+		}
+		client.PrintMessage("Warning: quota could not be checked: %s", err)
+		return nil
+	}
+	if len(violations) > 0 {
+		if enforceQuota {
+			return errors.New("reservation exceeds quota:\n" + strings.Join(violations, "\n"))
+		}
+		for _, violation := range violations {
+			client.PrintMessage("Warning: %s", violation)
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,149 @@
+package queries
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// reservation returns the resources to reserve for role: cpus and mem if they are set, and the resources given
+// as name:type:value.
+func reservation(role string, principal string, cpus float64, mem float64, specs []string) ([]mesos.Resource, error) {
+	var resources []mesos.Resource
+	if cpus > 0 {
+		resources = append(resources, resource("cpus", role, principal, cpus))
+	}
+	if mem > 0 {
+		resources = append(resources, resource("mem", role, principal, mem))
+	}
+
+	for _, spec := range specs {
+		r, err := parseResource(spec, role, principal)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, r)
+	}
+
+	if len(resources) == 0 {
+		return nil, errors.New("no resources to reserve")
+	}
+
+	return resources, nil
+}
+
+// parseResource parses a resource given as name:type:value, e.g. gpus:scalar:2,
+// ephemeral_ports:ranges:31000-31010,32000-32001 or network:set:a,b.
+func parseResource(spec string, role string, principal string) (mesos.Resource, error) {
+	parts := strings.SplitN(spec, ":", 3)
+	if len(parts) != 3 || parts[0] == "" {
+		return mesos.Resource{}, fmt.Errorf("invalid resource %s, expected name:type:value", spec)
+	}
+	name, value := parts[0], strings.Trim(parts[2], "[]{}")
+
+	r := resource(name, role, principal, 0)
+	switch strings.ToLower(parts[1]) {
+	case "scalar":
+		var amount float64
+		var err error
+		switch name {
+		case "cpus":
+			amount, err = ParseCPUs(value)
+		case "mem", "disk":
+			amount, err = ParseMegabytes(value)
+		default:
+			amount, err = strconv.ParseFloat(value, 64)
+			if err == nil && (!isFinite(amount) || amount < 0) {
+				err = errors.New("invalid amount")
+			}
+			amount = roundScalar(amount)
+		}
+		if err != nil {
+			return r, fmt.Errorf("invalid amount of %s: %s", name, value)
+		}
+		r.Scalar.Value = amount
+	case "ranges":
+		r.Type = mesos.RANGES.Enum()
+		r.Scalar = nil
+		r.Ranges = &mesos.Value_Ranges{}
+		for _, rg := range strings.Split(value, ",") {
+			bounds := strings.SplitN(rg, "-", 2)
+			begin, err := strconv.ParseUint(strings.TrimSpace(bounds[0]), 10, 64)
+			if err != nil {
+				return r, fmt.Errorf("invalid range %s of %s", rg, name)
+			}
+			end := begin
+			if len(bounds) == 2 {
+				end, err = strconv.ParseUint(strings.TrimSpace(bounds[1]), 10, 64)
+				if err != nil || end < begin {
+					return r, fmt.Errorf("invalid range %s of %s", rg, name)
+				}
+			}
+			r.Ranges.Range = append(r.Ranges.Range, mesos.Value_Range{Begin: begin, End: end})
+		}
+	case "set":
+		r.Type = mesos.SET.Enum()
+		r.Scalar = nil
+		r.Set = &mesos.Value_Set{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				r.Set.Item = append(r.Set.Item, item)
+			}
+		}
+	default:
+		return r, fmt.Errorf("invalid type %s of %s, expected scalar, ranges or set", parts[1], name)
+	}
+
+	return r, nil
+}
+
+// validateResources checks that an agent advertises resources with the names and types of the given resources.
+// With checkTotals, it also checks that the amounts fit into what the agent advertises, for disks per source.
+func validateResources(agent master.Response_GetAgents_Agent, checkTotals bool, resources ...mesos.Resource) error {
+	advertised := make(map[string][]mesos.Resource)
+	for _, r := range agent.AgentInfo.GetResources() {
+		advertised[r.GetName()] = append(advertised[r.GetName()], unreservedView(r))
+	}
+
+	for _, r := range resources {
+		available, ok := advertised[r.GetName()]
+		if !ok {
+			var names []string
+			for name := range advertised {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("agent %s does not advertise %s, it advertises %s", agent.AgentInfo.Hostname, r.GetName(), strings.Join(names, ", "))
+		}
+		if available[0].GetType() != r.GetType() {
+			return fmt.Errorf("%s is %s on agent %s, not %s", r.GetName(), available[0].GetType(), agent.AgentInfo.Hostname, r.GetType())
+		}
+
+		if r.GetType() == mesos.SCALAR {
+			if r.GetScalar().GetValue() <= 0 {
+				return fmt.Errorf("amount of %s must be positive", r.GetName())
+			}
+			if total := sourceTotal(available, r); checkTotals && r.GetScalar().GetValue() > total {
+				return fmt.Errorf("need %s %s, agent %s has %s in total", formatScalar(r.GetName(), r.GetScalar().GetValue()), scalarName(r), agent.AgentInfo.Hostname, formatScalar(r.GetName(), total))
+			}
+			continue
+		}
+
+		contained := !checkTotals
+		for _, a := range available {
+			if a.Contains(unreservedView(r)) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			return fmt.Errorf("%s %s is not advertised by agent %s", r.GetName(), resourceValue(r), agent.AgentInfo.Hostname)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package queries
+
+import (
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	"testing"
+)
+
+// unreserved returns an unreserved scalar resource.
+func unreserved(name string, value float64) mesos.Resource {
+	return mesos.Resource{Name: name, Type: mesos.SCALAR.Enum(), Scalar: &mesos.Value_Scalar{Value: value}}
+}
+
+// mountDisk returns r as a disk from a MOUNT source at root.
+func mountDisk(r mesos.Resource, root string) mesos.Resource {
+	r.Disk = &mesos.Resource_DiskInfo{Source: &mesos.Resource_DiskInfo_Source{
+		Type:  mesos.Resource_DiskInfo_Source_MOUNT,
+		Mount: &mesos.Resource_DiskInfo_Source_Mount{Root: &root},
+	}}
+	return r
+}
+
+func portRanges(name string, ranges ...mesos.Value_Range) mesos.Resource {
+	return mesos.Resource{Name: name, Type: mesos.RANGES.Enum(), Ranges: &mesos.Value_Ranges{Range: ranges}}
+}
+
+func TestParseResource(t *testing.T) {
+	tests := []struct {
+		spec      string
+		wantType  mesos.Value_Type
+		wantValue string
+		wantErr   bool
+	}{
+		{"gpus:scalar:2", mesos.SCALAR, "2.000", false},
+		{"gpus:SCALAR:0.1234", mesos.SCALAR, "0.123", false},
+		{"cpus:scalar:500m", mesos.SCALAR, "0.500", false},
+		{"mem:scalar:4G", mesos.SCALAR, "4096.000", false},
+		{"ephemeral_ports:ranges:31000-31010,32000", mesos.RANGES, "[31000-31010,32000-32000]", false},
+		{"ports:ranges:[31000-31010]", mesos.RANGES, "[31000-31010]", false},
+		{"network:set:a, b,", mesos.SET, "{a,b}", false},
+		{"network:set:{a,b}", mesos.SET, "{a,b}", false},
+		{"gpus:2", 0, "", true},
+		{":scalar:2", 0, "", true},
+		{"gpus:scalar:two", 0, "", true},
+		{"gpus:scalar:-1", 0, "", true},
+		{"gpus:scalar:NaN", 0, "", true},
+		{"gpus:scalar:Inf", 0, "", true},
+		{"cpus:scalar:NaN", 0, "", true},
+		{"mem:scalar:4X", 0, "", true},
+		{"ports:ranges:31010-31000", 0, "", true},
+		{"ports:ranges:a-b", 0, "", true},
+		{"gpus:text:2", 0, "", true},
+	}
+
+	for _, tt := range tests {
+		r, err := parseResource(tt.spec, "role", "principal")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseResource(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if r.GetType() != tt.wantType || resourceValue(r) != tt.wantValue {
+			t.Errorf("parseResource(%q) = %s %s, want %s %s", tt.spec, r.GetType(), resourceValue(r), tt.wantType, tt.wantValue)
+		}
+		if r.GetRole() != "role" || r.GetReservation().GetPrincipal() != "principal" {
+			t.Errorf("parseResource(%q) reserved for %s by %s, want role by principal", tt.spec, r.GetRole(), r.GetReservation().GetPrincipal())
+		}
+	}
+}
+
+func TestValidateResources(t *testing.T) {
+	hostname := "node-a"
+	agent := master.Response_GetAgents_Agent{AgentInfo: mesos.AgentInfo{
+		Hostname: hostname,
+		Resources: []mesos.Resource{
+			unreserved("cpus", 4),
+			unreserved("disk", 1000),
+			mountDisk(unreserved("disk", 500000), "/dcos/volume0"),
+			portRanges("ports", mesos.Value_Range{Begin: 31000, End: 32000}),
+		},
+	}}
+
+	tests := []struct {
+		name        string
+		checkTotals bool
+		resources   []mesos.Resource
+		wantErr     bool
+	}{
+		{"fits", true, []mesos.Resource{resource("cpus", "r", "p", 4), resource("disk", "r", "p", 1000)}, false},
+		{"not advertised", false, []mesos.Resource{resource("gpus", "r", "p", 1)}, true},
+		{"wrong type", false, []mesos.Resource{portRanges("cpus", mesos.Value_Range{Begin: 1, End: 2})}, true},
+		{"not positive", false, []mesos.Resource{resource("cpus", "r", "p", 0)}, true},
+		{"over total", true, []mesos.Resource{resource("cpus", "r", "p", 5)}, true},
+		{"over total unchecked", false, []mesos.Resource{resource("cpus", "r", "p", 5)}, false},
+		{"root disk over total", true, []mesos.Resource{resource("disk", "r", "p", 2000)}, true},
+		{"mount disk", true, []mesos.Resource{mountDisk(resource("disk", "r", "p", 400000), "/dcos/volume0")}, false},
+		{"mount disk over total", true, []mesos.Resource{mountDisk(resource("disk", "r", "p", 501000), "/dcos/volume0")}, true},
+		{"ranges", true, []mesos.Resource{portRanges("ports", mesos.Value_Range{Begin: 31000, End: 31010})}, false},
+		{"ranges not advertised", true, []mesos.Resource{portRanges("ports", mesos.Value_Range{Begin: 80, End: 80})}, true},
+		{"ranges unchecked", false, []mesos.Resource{portRanges("ports", mesos.Value_Range{Begin: 80, End: 80})}, false},
+	}
+
+	for _, tt := range tests {
+		if err := validateResources(agent, tt.checkTotals, tt.resources...); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateResources() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package queries
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/minyk/dcos-resources/client"
+	"io/ioutil"
+	"time"
+)
+
+type RestoreResources struct {
+	PrefixMesosMasterApiV1 func() string
+	PrefixMesosSlaveApiV0  func(string) string
+	PrefixMesosSlaveApiV1  func(string) string
+}
+
+func NewRestoreResources() *RestoreResources {
+	return &RestoreResources{
+		PrefixMesosMasterApiV1: func() string { return "/mesos/api/v1/" },
+		PrefixMesosSlaveApiV0:  func(agentid string) string { return "/agent/" + agentid },
+		PrefixMesosSlaveApiV1:  func(agentid string) string { return "/agent/" + agentid + "/api/v1" },
+	}
+}
+
+// Restore re-creates the reservations and persistent volumes of a snapshot which are missing on the agents.
+// agentMap maps agent IDs of the snapshot to the current agent IDs, for agents which re-registered with a new ID.
+// agentid is resolved like the agents of other commands, but taken as it is if no current agent matches, since
+// the agent of a snapshot may be gone.
+func (q *RestoreResources) Restore(file string, agentid string, agentMap map[string]string, wait time.Duration) error {
+	if agentid != "" {
+		if resolved, err := resolveAgent(q.PrefixMesosMasterApiV1(), agentid); err == nil {
+			agentid = resolved
+		}
+	}
+
+	agents, err := readReservations(file, agentid)
+	if err != nil {
+		return err
+	}
+
+	// only the new IDs are resolved, the agents of the snapshot may be gone
+	targets := make(map[string]string)
+	for old, agent := range agentMap {
+		targets[old], err = resolveAgent(q.PrefixMesosMasterApiV1(), agent)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, agent := range agents {
+		target := agent.AgentID
+		if mapped, ok := targets[agent.AgentID]; ok {
+			target = mapped
+		}
+		client.PrintMessage("Restoring reservations of %s on %s", agent.AgentID, target)
+
+		live, err := listResources(q.PrefixMesosSlaveApiV0(target))
+		if err != nil {
+			return err
+		}
+
+		// counted, as reservations without a resource ID can share a key
+		existing := make(map[string]int)
+		for _, resources := range live {
+			for _, r := range resources {
+				existing[resourceKey(target, withoutVolume(r))]++
+				if r.GetDisk().GetPersistence().GetID() != "" {
+					existing[resourceKey(target, r)]++
+				}
+			}
+		}
+
+		var added []mesos.Resource
+		for _, resources := range agent.AgentReservedResourcesFull {
+			for _, r := range resources {
+				rid, _ := getIDsFromLabels(r.GetReservation().GetLabels().GetLabels())
+				reservation := withoutVolume(r)
+
+				if key := resourceKey(target, reservation); existing[key] > 0 {
+					existing[key]--
+				} else {
+					err = reserveResources(q.PrefixMesosMasterApiV1(), target, reservation)
+					if err != nil {
+						return err
+					}
+					added = append(added, reservation)
+					client.PrintMessage("Reserved %s %s for %s: %s", r.GetName(), resourceValue(r), r.GetRole(), rid)
+				}
+
+				if r.GetDisk().GetPersistence().GetID() == "" {
+					continue
+				}
+				if key := resourceKey(target, r); existing[key] > 0 {
+					existing[key]--
+				} else {
+					volume := r
+					volume.AllocationInfo = nil
+					err = createVolumes(q.PrefixMesosMasterApiV1(), target, volume)
+					if err != nil {
+						return err
+					}
+					added = append(added, volume)
+					client.PrintMessage("Created persistent volume %s for %s: %s", r.GetDisk().GetPersistence().GetID(), r.GetRole(), rid)
+				}
+			}
+		}
+
+		if len(added) > 0 {
+			err = waitForChange(q.PrefixMesosSlaveApiV0(target), target, wait, live, added, nil)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	client.PrintMessage("Restore is successful.")
+
+	return nil
+}
+
+// readReservations reads either a snapshot file or a single agent's reserved_resources_full dump. The latter is
+// restored onto agentid.
+func readReservations(file string, agentid string) ([]AgentReservations, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := Snapshot{}
+	err = json.Unmarshal(content, &snapshot)
+	if err == nil && snapshot.Version != 0 {
+		if snapshot.Version != snapshotVersion {
+			return nil, errors.New("unsupported snapshot version in " + file)
+		}
+		if agentid == "" {
+			return snapshot.Agents, nil
+		}
+		for _, agent := range snapshot.Agents {
+			if agent.AgentID == agentid {
+				return []AgentReservations{agent}, nil
+			}
+		}
+		return nil, errors.New("no reservations of agent " + agentid + " in " + file)
+	}
+
+	if agentid == "" {
+		return nil, errors.New("agent id is required to restore a reserved_resources_full dump")
+	}
+
+	state := AgentState{}
+	err = json.Unmarshal(content, &state)
+	if err == nil && len(state.AgentReservedResourcesFull) > 0 {
+		return []AgentReservations{{AgentID: agentid, AgentReservedResourcesFull: state.AgentReservedResourcesFull}}, nil
+	}
+
+	reservations := ReservedResourcesFull{}
+	err = json.Unmarshal(content, &reservations)
+	if err != nil {
+		return nil, err
+	}
+
+	return []AgentReservations{{AgentID: agentid, AgentReservedResourcesFull: reservations}}, nil
+}
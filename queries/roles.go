@@ -0,0 +1,88 @@
+package queries
+
+import (
+	"github.com/mesos/mesos-go/api/v1/lib"
+	mastercalls "github.com/mesos/mesos-go/api/v1/lib/master/calls"
+	"github.com/minyk/dcos-resources/client"
+	"sort"
+	"strings"
+)
+
+type RoleList struct {
+	PrefixMesosMasterApiV1 func() string
+	PrefixMesosSlaveApiV0  func(string) string
+	PrefixMesosSlaveApiV1  func(string) string
+}
+
+func NewRoleList() *RoleList {
+	return &RoleList{
+		PrefixMesosMasterApiV1: func() string { return "/mesos/api/v1/" },
+		PrefixMesosSlaveApiV0:  func(agentid string) string { return "/agent/" + agentid },
+		PrefixMesosSlaveApiV1:  func(agentid string) string { return "/agent/" + agentid + "/api/v1" },
+	}
+}
+
+// ListRoles prints the roles known to the master, limited to the subtree of root if it is not empty.
+// If reserved is set, the resources reserved for each role on all agents are summed up as well.
+func (q *RoleList) ListRoles(root string, reserved bool) error {
+	response, err := callMaster(q.PrefixMesosMasterApiV1(), mastercalls.GetRoles())
+	if err != nil {
+		return err
+	}
+
+	roles := make(map[string]mesos.Role)
+	for _, role := range response.GetGetRoles().GetRoles() {
+		roles[role.Name] = role
+	}
+
+	var totals map[string]map[string]float64
+	if reserved {
+		totals, err = getReservedTotals(q.PrefixMesosMasterApiV1(), q.PrefixMesosSlaveApiV0)
+		if err != nil {
+			return err
+		}
+		for name := range totals {
+			if _, ok := roles[name]; !ok {
+				roles[name] = mesos.Role{Name: name, Weight: 1}
+			}
+		}
+	}
+
+	var names []string
+	for name := range roles {
+		if root == "" || isSubrole(name, root) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	if reserved {
+		client.PrintMessage("Role\t\tWeight\t\tFrameworks\t\tAllocated\t\tReserved")
+	} else {
+		client.PrintMessage("Role\t\tWeight\t\tFrameworks\t\tAllocated")
+	}
+	for _, name := range names {
+		role := roles[name]
+
+		var frameworks []string
+		for _, framework := range role.GetFrameworks() {
+			frameworks = append(frameworks, framework.Value)
+		}
+
+		allocated := make(map[string]float64)
+		for _, r := range role.GetResources() {
+			if r.GetType() == mesos.SCALAR {
+				allocated[r.GetName()] += r.GetScalar().GetValue()
+			}
+		}
+
+		indented := strings.Repeat("  ", roleDepth(name)) + name
+		if reserved {
+			client.PrintMessage("%s\t\t%f\t\t%s\t\t%s\t\t%s", indented, role.GetWeight(), strings.Join(frameworks, ","), formatScalars(allocated), formatScalars(totals[name]))
+		} else {
+			client.PrintMessage("%s\t\t%f\t\t%s\t\t%s", indented, role.GetWeight(), strings.Join(frameworks, ","), formatScalars(allocated))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package queries
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	"github.com/minyk/dcos-resources/client"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReserveOnSelector reserves the same resources, or the same share of their resources, on count agents, or on
+// all agents if all is set, which match the selector and have the unreserved capacity for them.
+func (q *ReserveResources) ReserveOnSelector(selector string, count int, all bool, role string, principal string, cpus Amount, mem Amount, specs []string, enforceQuota bool, wait time.Duration) error {
+	match, err := parseSelector(selector)
+	if err != nil {
+		return err
+	}
+
+	agents, err := getAgents(q.PrefixMesosMasterApiV1())
+	if err != nil {
+		return err
+	}
+	sort.Slice(agents, func(i, j int) bool {
+		return agents[i].AgentInfo.Hostname < agents[j].AgentInfo.Hostname
+	})
+
+	type candidate struct {
+		agentid   string
+		resources []mesos.Resource
+		before    ReservedResourcesFull
+	}
+
+	var candidates []candidate
+	for _, agent := range agents {
+		if !matchSelector(agent, match) {
+			continue
+		}
+		agentid := agent.AgentInfo.ID.Value
+
+		resources, err := agentReservation(agent, role, principal, cpus, mem, specs)
+		if err != nil {
+			return err
+		}
+
+		err = validateResources(agent, true, resources...)
+		if err != nil {
+			client.PrintMessage("Skipped %s: %s", agent.AgentInfo.Hostname, err)
+			continue
+		}
+
+		state, err := getAgentState(q.PrefixMesosSlaveApiV0(agentid))
+		if err != nil {
+			return err
+		}
+		err = checkCapacity(agentid, availableResources(agent, state), resources...)
+		if err != nil {
+			client.PrintMessage("Skipped %s: %s", agent.AgentInfo.Hostname, err)
+			continue
+		}
+		candidates = append(candidates, candidate{agentid: agentid, resources: resources, before: state.AgentReservedResourcesFull})
+	}
+
+	if !all {
+		if len(candidates) < count {
+			return fmt.Errorf("%d agents matching %s have the capacity, %d requested", len(candidates), selector, count)
+		}
+		candidates = candidates[:count]
+	}
+	if len(candidates) == 0 {
+		return errors.New("no agents matching " + selector + " have the capacity")
+	}
+
+	var total []mesos.Resource
+	for _, c := range candidates {
+		total = append(total, c.resources...)
+	}
+	err = q.checkQuota(role, enforceQuota, total...)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, c := range candidates {
+		err = reserveResources(q.PrefixMesosMasterApiV1(), c.agentid, c.resources...)
+		if err != nil {
+			client.PrintMessage("Reservation on %s failed: %s", c.agentid, err)
+			failed++
+			continue
+		}
+		client.PrintMessage("Reservation on %s is successful.", c.agentid)
+
+		err = waitForChange(q.PrefixMesosSlaveApiV0(c.agentid), c.agentid, wait, c.before, c.resources, nil)
+		if err != nil {
+			client.PrintMessage("Reservation on %s is not confirmed: %s", c.agentid, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("reservation failed on %d of %d agents", failed, len(candidates))
+	}
+
+	return nil
+}
+
+// parseSelector parses a selector like "rack=r1,zone=us-east-1a". The keys region and zone match the fault
+// domain of an agent, hostname its hostname, and any other key an attribute.
+func parseSelector(selector string) (map[string]string, error) {
+	match := make(map[string]string)
+	for _, term := range strings.Split(selector, ",") {
+		parts := strings.SplitN(term, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, errors.New("invalid selector term: " + term)
+		}
+		// an empty value would match every agent without the attribute
+		if strings.TrimSpace(parts[1]) == "" {
+			return nil, errors.New("empty value in selector term: " + term)
+		}
+		match[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return match, nil
+}
+
+func matchSelector(agent master.Response_GetAgents_Agent, match map[string]string) bool {
+	for key, value := range match {
+		if agentLabel(agent, key) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// agentLabel returns the region, zone, hostname or an attribute of an agent.
+func agentLabel(agent master.Response_GetAgents_Agent, key string) string {
+	faultDomain := agent.AgentInfo.GetDomain().GetFaultDomain()
+	switch key {
+	case "hostname":
+		return agent.AgentInfo.Hostname
+	case "region":
+		if faultDomain != nil {
+			return faultDomain.Region.Name
+		}
+		return ""
+	case "zone":
+		if faultDomain != nil {
+			return faultDomain.Zone.Name
+		}
+		return ""
+	default:
+		return agentAttribute(agent, key)
+	}
+}
+
+func agentAttribute(agent master.Response_GetAgents_Agent, name string) string {
+	for _, a := range agent.AgentInfo.GetAttributes() {
+		if a.GetName() == name {
+			return attributeValue(a)
+		}
+	}
+	return ""
+}
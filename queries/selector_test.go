@@ -0,0 +1,66 @@
+package queries
+
+import (
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	"reflect"
+	"testing"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     map[string]string
+		wantErr  bool
+	}{
+		{"rack=r1", map[string]string{"rack": "r1"}, false},
+		{"rack = r1, zone=us-east-1a", map[string]string{"rack": "r1", "zone": "us-east-1a"}, false},
+		{"os=linux=5", map[string]string{"os": "linux=5"}, false},
+		{"", nil, true},
+		{"rack", nil, true},
+		{"=r1", nil, true},
+		{" =r1", nil, true},
+		{"rack=", nil, true},
+		{"rack= ", nil, true},
+		{"rack=r1,zone=", nil, true},
+		{"rack=r1,", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSelector(tt.selector)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSelector(%q) error = %v, wantErr %v", tt.selector, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSelector(%q) = %v, want %v", tt.selector, got, tt.want)
+		}
+	}
+}
+
+func TestMatchSelector(t *testing.T) {
+	agent := testAgent("S0", "node-a", "10.0.0.1", "us-east-1a", map[string]string{"rack": "r1"})
+	noDomain := testAgent("S1", "node-b", "10.0.0.2", "", nil)
+
+	tests := []struct {
+		agent master.Response_GetAgents_Agent
+		match map[string]string
+		want  bool
+	}{
+		{agent, map[string]string{}, true},
+		{agent, map[string]string{"rack": "r1"}, true},
+		{agent, map[string]string{"rack": "r2"}, false},
+		{agent, map[string]string{"zone": "us-east-1a", "region": "us-east-1"}, true},
+		{agent, map[string]string{"zone": "us-east-1b"}, false},
+		{agent, map[string]string{"hostname": "node-a"}, true},
+		{agent, map[string]string{"rack": "r1", "hostname": "node-b"}, false},
+		{agent, map[string]string{"os": "linux"}, false},
+		{noDomain, map[string]string{"zone": "us-east-1a"}, false},
+		{noDomain, map[string]string{"zone": ""}, true},
+	}
+
+	for _, tt := range tests {
+		if got := matchSelector(tt.agent, tt.match); got != tt.want {
+			t.Errorf("matchSelector(%s, %v) = %v, want %v", tt.agent.AgentInfo.Hostname, tt.match, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package queries
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/minyk/dcos-resources/client"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+)
+
+const snapshotVersion = 1
+
+// Snapshot is the file format written by 'snapshot save'.
+type Snapshot struct {
+	Version     int                 `json:"version"`
+	Created     string              `json:"created"`
+	Agents      []AgentReservations `json:"agents"`
+	Unreachable []string            `json:"unreachable_agents,omitempty"`
+}
+
+type AgentReservations struct {
+	AgentID                    string                `json:"agent_id"`
+	AgentReservedResourcesFull ReservedResourcesFull `json:"reserved_resources_full"`
+}
+
+type ResourceSnapshot struct {
+	PrefixMesosMasterApiV1 func() string
+	PrefixMesosSlaveApiV0  func(string) string
+	PrefixMesosSlaveApiV1  func(string) string
+}
+
+func NewResourceSnapshot() *ResourceSnapshot {
+	return &ResourceSnapshot{
+		PrefixMesosMasterApiV1: func() string { return "/mesos/api/v1/" },
+		PrefixMesosSlaveApiV0:  func(agentid string) string { return "/agent/" + agentid },
+		PrefixMesosSlaveApiV1:  func(agentid string) string { return "/agent/" + agentid + "/api/v1" },
+	}
+}
+
+func (q *ResourceSnapshot) SaveSnapshot(file string) error {
+	snapshot, err := takeSnapshot(q.PrefixMesosMasterApiV1(), q.PrefixMesosSlaveApiV0)
+	if err != nil {
+		return err
+	}
+
+	err = writeSnapshot(file, snapshot)
+	if err != nil {
+		return err
+	}
+
+	client.PrintMessage("Saved reservations of %d agents to %s", len(snapshot.Agents), file)
+	if len(snapshot.Unreachable) > 0 {
+		client.PrintMessage("%d agents could not be reached: %s", len(snapshot.Unreachable), strings.Join(snapshot.Unreachable, ", "))
+	}
+
+	return nil
+}
+
+// DiffSnapshot compares two snapshot files. If after is empty, the live state of the cluster is used instead.
+func (q *ResourceSnapshot) DiffSnapshot(before string, after string) error {
+	beforeSnapshot, err := readSnapshot(before)
+	if err != nil {
+		return err
+	}
+
+	var afterSnapshot Snapshot
+	if after == "" {
+		afterSnapshot, err = takeSnapshot(q.PrefixMesosMasterApiV1(), q.PrefixMesosSlaveApiV0)
+	} else {
+		afterSnapshot, err = readSnapshot(after)
+	}
+	if err != nil {
+		return err
+	}
+
+	// agents missing from either side would show all their reservations as changed
+	skip := make(map[string]bool)
+	for _, agentid := range append(beforeSnapshot.Unreachable, afterSnapshot.Unreachable...) {
+		skip[agentid] = true
+	}
+	for agentid := range skip {
+		client.PrintMessage("Skipped unreachable agent %s", agentid)
+	}
+
+	beforeResources := flattenSnapshot(beforeSnapshot, skip)
+	afterResources := flattenSnapshot(afterSnapshot, skip)
+
+	var keys []string
+	for key := range beforeResources {
+		keys = append(keys, key)
+	}
+	for key := range afterResources {
+		if _, ok := beforeResources[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := 0
+	client.PrintMessage("Change\t\tAgentID\t\tRole\t\tPrincipal\t\tType\t\tValue\t\tID\t\tPersistentID")
+	for _, key := range keys {
+		befores, afters := unchangedRemoved(beforeResources[key], afterResources[key])
+		for i := 0; i < len(befores) || i < len(afters); i++ {
+			switch {
+			case i >= len(befores):
+				printSnapshotChange("+", afters[i], resourceValue(afters[i].resource))
+			case i >= len(afters):
+				printSnapshotChange("-", befores[i], resourceValue(befores[i].resource))
+			default:
+				printSnapshotChange("~", afters[i], resourceValue(befores[i].resource)+" -> "+resourceValue(afters[i].resource))
+			}
+			changes++
+		}
+	}
+	client.PrintMessage("%d reservations changed.", changes)
+
+	return nil
+}
+
+type snapshotResource struct {
+	agentID  string
+	resource mesos.Resource
+}
+
+func printSnapshotChange(change string, s snapshotResource, value string) {
+	r := s.resource
+	rid, _ := getIDsFromLabels(r.GetReservation().GetLabels().GetLabels())
+	client.PrintMessage("%s\t\t%s\t\t%s\t\t%s\t\t%s\t\t%s\t\t%s\t\t%s", change, s.agentID, r.GetRole(), r.GetReservation().GetPrincipal(), r.GetName(), value, rid, r.GetDisk().GetPersistence().GetID())
+}
+
+// flattenSnapshot groups the reservations of a snapshot by resourceKey. Reservations without a resource ID can
+// share a key, so each key holds a list.
+func flattenSnapshot(snapshot Snapshot, skip map[string]bool) map[string][]snapshotResource {
+	resources := make(map[string][]snapshotResource)
+	for _, agent := range snapshot.Agents {
+		if skip[agent.AgentID] {
+			continue
+		}
+		for _, roleResources := range agent.AgentReservedResourcesFull {
+			for _, r := range roleResources {
+				key := resourceKey(agent.AgentID, r)
+				resources[key] = append(resources[key], snapshotResource{agentID: agent.AgentID, resource: r})
+			}
+		}
+	}
+	return resources
+}
+
+// unchangedRemoved drops the reservations which are the same before and after, leaving the changed ones in order.
+func unchangedRemoved(befores []snapshotResource, afters []snapshotResource) ([]snapshotResource, []snapshotResource) {
+	var changed []snapshotResource
+	afters = append([]snapshotResource{}, afters...)
+next:
+	for _, b := range befores {
+		for i, a := range afters {
+			if b.resource.Equivalent(a.resource) {
+				afters = append(afters[:i], afters[i+1:]...)
+				continue next
+			}
+		}
+		changed = append(changed, b)
+	}
+	return changed, afters
+}
+
+func takeSnapshot(masterUrl string, slaveUrl func(string) string) (Snapshot, error) {
+	agents, err := getAgentList(masterUrl)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	return snapshotAgents(agents, slaveUrl)
+}
+
+func snapshotAgents(agents []string, slaveUrl func(string) string) (Snapshot, error) {
+	snapshot := Snapshot{
+		Version: snapshotVersion,
+		Created: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	for _, agentid := range agents {
+		resources, err := listResources(slaveUrl(agentid))
+		if err != nil {
+			client.PrintMessage("Skipped agent %s: %s", agentid, err)
+			snapshot.Unreachable = append(snapshot.Unreachable, agentid)
+			continue
+		}
+		snapshot.Agents = append(snapshot.Agents, AgentReservations{AgentID: agentid, AgentReservedResourcesFull: resources})
+	}
+
+	if len(agents) > 0 && len(snapshot.Agents) == 0 {
+		return snapshot, fmt.Errorf("none of %d agents could be reached", len(agents))
+	}
+
+	return snapshot, nil
+}
+
+func writeSnapshot(file string, snapshot Snapshot) error {
+	content, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, content, 0644)
+}
+
+func readSnapshot(file string) (Snapshot, error) {
+	snapshot := Snapshot{}
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return snapshot, err
+	}
+
+	err = json.Unmarshal(content, &snapshot)
+	if err != nil {
+		return snapshot, err
+	}
+
+	if snapshot.Version != snapshotVersion {
+		return snapshot, fmt.Errorf("unsupported snapshot version %d in %s", snapshot.Version, file)
+	}
+
+	return snapshot, nil
+}
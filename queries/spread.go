@@ -0,0 +1,105 @@
+package queries
+
+import (
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/minyk/dcos-resources/client"
+	"sort"
+)
+
+type SpreadReport struct {
+	PrefixMesosMasterApiV1 func() string
+	PrefixMesosSlaveApiV0  func(string) string
+	PrefixMesosSlaveApiV1  func(string) string
+}
+
+func NewSpreadReport() *SpreadReport {
+	return &SpreadReport{
+		PrefixMesosMasterApiV1: func() string { return "/mesos/api/v1/" },
+		PrefixMesosSlaveApiV0:  func(agentid string) string { return "/agent/" + agentid },
+		PrefixMesosSlaveApiV1:  func(agentid string) string { return "/agent/" + agentid + "/api/v1" },
+	}
+}
+
+type spreadGroup struct {
+	region    string
+	zone      string
+	attribute string
+	agents    int
+	reserved  map[string]float64
+}
+
+// Spread prints the reservations of a role grouped by the region and zone of their agents, and optionally by an
+// attribute. It warns if all reservations are in a single zone.
+func (q *SpreadReport) Spread(role string, attribute string, includeChildren bool) error {
+	agents, err := getAgents(q.PrefixMesosMasterApiV1())
+	if err != nil {
+		return err
+	}
+
+	groups := make(map[string]*spreadGroup)
+	zones := make(map[string]bool)
+	for _, agent := range agents {
+		resourcesFull, err := listResources(q.PrefixMesosSlaveApiV0(agent.AgentInfo.ID.Value))
+		if err != nil {
+			return err
+		}
+
+		var resources ResourceRole
+		for r, roleResources := range resourcesFull {
+			if r == role || (includeChildren && isSubrole(r, role)) {
+				resources = append(resources, roleResources...)
+			}
+		}
+		if len(resources) == 0 {
+			continue
+		}
+
+		region, zone := spreadValue(agent, "region"), spreadValue(agent, "zone")
+		value := ""
+		if attribute != "" {
+			value = spreadValue(agent, attribute)
+		}
+
+		key := region + "/" + zone + "/" + value
+		group, ok := groups[key]
+		if !ok {
+			group = &spreadGroup{region: region, zone: zone, attribute: value, reserved: make(map[string]float64)}
+			groups[key] = group
+		}
+		group.agents++
+		for _, r := range resources {
+			if r.GetType() == mesos.SCALAR {
+				group.reserved[r.GetName()] += r.GetScalar().GetValue()
+			}
+		}
+		zones[region+"/"+zone] = true
+	}
+
+	var keys []string
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if attribute == "" {
+		client.PrintMessage("Region\t\tZone\t\tAgents\t\tReserved")
+	} else {
+		client.PrintMessage("Region\t\tZone\t\t%s\t\tAgents\t\tReserved", attribute)
+	}
+	for _, key := range keys {
+		group := groups[key]
+		if attribute == "" {
+			client.PrintMessage("%s\t\t%s\t\t%d\t\t%s", group.region, group.zone, group.agents, formatScalars(group.reserved))
+		} else {
+			client.PrintMessage("%s\t\t%s\t\t%s\t\t%d\t\t%s", group.region, group.zone, group.attribute, group.agents, formatScalars(group.reserved))
+		}
+	}
+
+	if len(zones) == 1 {
+		for zone := range zones {
+			client.PrintMessage("Warning: all reservations of %s are in a single zone: %s", role, zone)
+		}
+	}
+
+	return nil
+}
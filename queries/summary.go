@@ -0,0 +1,101 @@
+package queries
+
+import (
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/minyk/dcos-resources/client"
+	"sort"
+	"strings"
+)
+
+type ResourceSummary struct {
+	PrefixMesosMasterApiV1 func() string
+	PrefixMesosSlaveApiV0  func(string) string
+	PrefixMesosSlaveApiV1  func(string) string
+}
+
+func NewResourceSummary() *ResourceSummary {
+	return &ResourceSummary{
+		PrefixMesosMasterApiV1: func() string { return "/mesos/api/v1/" },
+		PrefixMesosSlaveApiV0:  func(agentid string) string { return "/agent/" + agentid },
+		PrefixMesosSlaveApiV1:  func(agentid string) string { return "/agent/" + agentid + "/api/v1" },
+	}
+}
+
+// Summary prints the reserved resources per role of an agent, or of all agents if agentid is empty.
+// With includeChildren, the roles are printed as a tree with the totals of each subtree.
+func (q *ResourceSummary) Summary(agentid string, role string, includeChildren bool) error {
+	var totals map[string]map[string]float64
+	if agentid == "" {
+		var err error
+		totals, err = getReservedTotals(q.PrefixMesosMasterApiV1(), q.PrefixMesosSlaveApiV0)
+		if err != nil {
+			return err
+		}
+	} else {
+		agentid, err := resolveAgent(q.PrefixMesosMasterApiV1(), agentid)
+		if err != nil {
+			return err
+		}
+		resourcesFull, err := listResources(q.PrefixMesosSlaveApiV0(agentid))
+		if err != nil {
+			return err
+		}
+		totals = make(map[string]map[string]float64)
+		for r, resources := range resourcesFull {
+			totals[r] = make(map[string]float64)
+			for _, resource := range resources {
+				if resource.GetType() == mesos.SCALAR {
+					totals[r][resource.GetName()] += resource.GetScalar().GetValue()
+				}
+			}
+		}
+	}
+
+	if !includeChildren {
+		var roles []string
+		for r := range totals {
+			if role == "" || r == role {
+				roles = append(roles, r)
+			}
+		}
+		sort.Strings(roles)
+
+		client.PrintMessage("Role\t\tReserved")
+		for _, r := range roles {
+			client.PrintMessage("%s\t\t%s", r, formatScalars(totals[r]))
+		}
+		return nil
+	}
+
+	// add the ancestors of every role, so each subtree has a row for its total
+	tree := make(map[string]bool)
+	for r := range totals {
+		parts := strings.Split(r, "/")
+		for i := range parts {
+			tree[strings.Join(parts[:i+1], "/")] = true
+		}
+	}
+
+	var roles []string
+	for r := range tree {
+		if role == "" || isSubrole(r, role) {
+			roles = append(roles, r)
+		}
+	}
+	sort.Strings(roles)
+
+	client.PrintMessage("Role\t\tReserved\t\tTotal")
+	for _, r := range roles {
+		subtree := make(map[string]float64)
+		for child, scalars := range totals {
+			if isSubrole(child, r) {
+				for name, value := range scalars {
+					subtree[name] += value
+				}
+			}
+		}
+		client.PrintMessage("%s\t\t%s\t\t%s", strings.Repeat("  ", roleDepth(r))+r, formatScalars(totals[r]), formatScalars(subtree))
+	}
+
+	return nil
+}
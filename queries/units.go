@@ -0,0 +1,148 @@
+package queries
+
+import (
+	"fmt"
+	"github.com/alecthomas/units"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// OutputUnits is the unit memory and disk are printed in: MB, GiB or auto.
+var OutputUnits = "MB"
+
+var megabytesPattern = regexp.MustCompile(`(?i)^([0-9]*\.?[0-9]+)\s*(?:([KMGTP])(?:i?B)?|B)?$`)
+
+var byteUnits = map[string]units.Base2Bytes{
+	"":  units.MiB,
+	"K": units.KiB,
+	"M": units.MiB,
+	"G": units.GiB,
+	"T": units.TiB,
+	"P": units.PiB,
+}
+
+// ParseMegabytes parses an amount of memory or disk like 512, 512MiB, 4G or 1.5TiB into MB, the unit of Mesos.
+// Plain numbers are MB. K, M, G, T and P are powers of 1024 with or without iB or B, as in Mesos, in any case.
+func ParseMegabytes(s string) (float64, error) {
+	match := megabytesPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return 0, fmt.Errorf("invalid amount %s, e.g. 512, 512MiB, 4G or 1.5TiB", s)
+	}
+
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, err
+	}
+
+	unit := byteUnits[strings.ToUpper(match[2])]
+	if match[2] == "" && strings.HasSuffix(strings.ToUpper(strings.TrimSpace(s)), "B") {
+		unit = 1
+	}
+
+	return roundScalar(value * float64(unit) / float64(units.MiB)), nil
+}
+
+// ParseCPUs parses an amount of cpus like 2, 0.5 or 500m (millicores).
+func ParseCPUs(s string) (float64, error) {
+	amount := strings.TrimSpace(s)
+	scale := 1.0
+	if strings.HasSuffix(amount, "m") {
+		amount = strings.TrimSuffix(amount, "m")
+		scale = 1000
+	}
+
+	value, err := strconv.ParseFloat(amount, 64)
+	if err != nil || !isFinite(value) || value < 0 {
+		return 0, fmt.Errorf("invalid amount of cpus %s, e.g. 2, 0.5 or 500m", s)
+	}
+
+	return roundScalar(value / scale), nil
+}
+
+// Amount is an amount of a scalar resource, either absolute or a percentage of what an agent advertises.
+type Amount struct {
+	Value   float64
+	Percent float64
+}
+
+// ParseAmount parses a percentage like 50%, or an absolute amount with parse.
+func ParseAmount(s string, parse func(string) (float64, error)) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasSuffix(s, "%") {
+		value, err := parse(s)
+		return Amount{Value: value}, err
+	}
+
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil || !isFinite(percent) || percent <= 0 || percent > 100 {
+		return Amount{}, fmt.Errorf("invalid percentage %s, expected more than 0 and at most 100", strings.TrimSuffix(s, "%"))
+	}
+	return Amount{Percent: percent}, nil
+}
+
+func (a Amount) IsSet() bool {
+	return a.Value > 0 || a.Percent > 0
+}
+
+func (a Amount) String() string {
+	if a.Percent > 0 {
+		return strconv.FormatFloat(a.Percent, 'f', -1, 64) + "%"
+	}
+	return strconv.FormatFloat(a.Value, 'f', -1, 64)
+}
+
+// of returns the amount on an agent which advertises total. Percentages are rounded down to the precision of
+// Mesos, so they never exceed the total.
+func (a Amount) of(total float64) float64 {
+	if a.Percent > 0 {
+		// the epsilon keeps float errors like 2.9999999 from being rounded down
+		return math.Floor(total*a.Percent*10+1e-6) / 1000
+	}
+	return a.Value
+}
+
+// isFinite reports whether a parsed value is neither NaN nor infinite, which strconv.ParseFloat accepts.
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
+// roundScalar rounds to the fixed point precision of scalar resources in Mesos, three decimal digits.
+func roundScalar(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
+
+// formatScalar formats an amount of a scalar resource with three decimal digits. Memory and disk are converted to
+// OutputUnits, MB without a suffix unless OutputUnits is auto.
+func formatScalar(name string, value float64) string {
+	if name != "mem" && name != "disk" {
+		return strconv.FormatFloat(roundScalar(value), 'f', 3, 64)
+	}
+
+	unit := OutputUnits
+	if unit == "auto" {
+		unit = "MB"
+		for _, u := range []string{"TiB", "GiB"} {
+			if math.Abs(value) >= megabytesPer(u) {
+				unit = u
+				break
+			}
+		}
+	}
+
+	if unit == "MB" {
+		formatted := strconv.FormatFloat(roundScalar(value), 'f', 3, 64)
+		if OutputUnits == "auto" {
+			formatted += "MB"
+		}
+		return formatted
+	}
+
+	return strconv.FormatFloat(value/megabytesPer(unit), 'f', 3, 64) + unit
+}
+
+// megabytesPer returns the number of MB in a GiB or TiB.
+func megabytesPer(unit string) float64 {
+	return float64(byteUnits[unit[:1]] / units.MiB)
+}
@@ -0,0 +1,136 @@
+package queries
+
+import "testing"
+
+func TestParseMegabytes(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{"512", 512, false},
+		{" 512 ", 512, false},
+		{"0.5", 0.5, false},
+		{"512MiB", 512, false},
+		{"512MB", 512, false},
+		{"512M", 512, false},
+		{"4G", 4096, false},
+		{"4GiB", 4096, false},
+		{"1.5TiB", 1572864, false},
+		{"1P", 1073741824, false},
+		{"1024K", 1, false},
+		{"1048576B", 1, false},
+		{"4g", 4096, false},
+		{"512mb", 512, false},
+		{"512 mib", 512, false},
+		{"1gib", 1024, false},
+		{"1.5TiB ", 1572864, false},
+		{"1048576b", 1, false},
+		{"2kib", 0.002, false},
+		{"", 0, true},
+		{"abc", 0, true},
+		{"-1", 0, true},
+		{"4X", 0, true},
+		{"4x", 0, true},
+		{"4gb b", 0, true},
+		{"NaN", 0, true},
+		{"Inf", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMegabytes(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMegabytes(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMegabytes(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseCPUs(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{"2", 2, false},
+		{" 0.5 ", 0.5, false},
+		{"500m", 0.5, false},
+		{"1m", 0.001, false},
+		{"0.1234", 0.123, false},
+		{"0", 0, false},
+		{"", 0, true},
+		{"m", 0, true},
+		{"two", 0, true},
+		{"-1", 0, true},
+		{"NaN", 0, true},
+		{"Inf", 0, true},
+		{"-Inf", 0, true},
+		{"infm", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseCPUs(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCPUs(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseCPUs(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Amount
+		wantErr bool
+	}{
+		{"4G", Amount{Value: 4096}, false},
+		{"50%", Amount{Percent: 50}, false},
+		{" 12.5% ", Amount{Percent: 12.5}, false},
+		{"100%", Amount{Percent: 100}, false},
+		{"0%", Amount{}, true},
+		{"-10%", Amount{}, true},
+		{"101%", Amount{}, true},
+		{"half%", Amount{}, true},
+		{"NaN%", Amount{}, true},
+		{"Inf%", Amount{}, true},
+		{"4X", Amount{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.in, ParseMegabytes)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAmount(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseAmount(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAmountOf(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		total  float64
+		want   float64
+	}{
+		{Amount{Value: 2}, 8, 2},
+		{Amount{Percent: 50}, 8, 4},
+		{Amount{Percent: 30}, 10, 3},
+		{Amount{Percent: 33}, 1, 0.33},
+		{Amount{Percent: 100}, 0.005, 0.005},
+		{Amount{Percent: 50}, 0.005, 0.002},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.of(tt.total); got != tt.want {
+			t.Errorf("%s of %v = %v, want %v", tt.amount, tt.total, got, tt.want)
+		}
+	}
+}
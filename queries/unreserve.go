@@ -5,6 +5,7 @@ import (
 	"github.com/mesos/mesos-go/api/v1/lib"
 	mastercalls "github.com/mesos/mesos-go/api/v1/lib/master/calls"
 	"github.com/minyk/dcos-resources/client"
+	"time"
 )
 
 type UnreserveResources struct {
@@ -21,7 +22,17 @@ func NewUnreserveResources() *UnreserveResources {
 	}
 }
 
-func (q *UnreserveResources) UnreserveResource(agentid string, role string, principal string, cpus float64, cpusLabel string, mem float64, memLabel string, disk float64, diskLabel string, frameworkLabel string) error {
+func (q *UnreserveResources) UnreserveResource(agentid string, role string, principal string, cpus float64, cpusLabel string, mem float64, memLabel string, disk float64, diskLabel string, frameworkLabel string, wait time.Duration) error {
+
+	agentid, err := resolveAgent(q.PrefixMesosMasterApiV1(), agentid)
+	if err != nil {
+		return err
+	}
+
+	before, err := reservationsBefore(q.PrefixMesosSlaveApiV0(agentid), wait)
+	if err != nil {
+		return err
+	}
 
 	var resources []mesos.Resource
 	if cpus > 0 {
@@ -34,13 +45,25 @@ func (q *UnreserveResources) UnreserveResource(agentid string, role string, prin
 		resources = append(resources, resourceWithLabel("disk", role, principal, disk, diskLabel, frameworkLabel))
 	}
 
-	q.UnreserveMesosResource(agentid, resources...)
-
-	return nil
+	err = q.UnreserveMesosResource(agentid, resources...)
+	if err != nil {
+		return err
+	}
 
+	return waitForChange(q.PrefixMesosSlaveApiV0(agentid), agentid, wait, before, nil, resources)
 }
 
-func (q *UnreserveResources) DestroyVolume(agentid string, role string, principal string, disk float64, resourceid string, frameworkid string, persistid string, containerpath string, hostpath string) error {
+func (q *UnreserveResources) DestroyVolume(agentid string, role string, principal string, disk float64, resourceid string, frameworkid string, persistid string, containerpath string, hostpath string, wait time.Duration) error {
+
+	agentid, err := resolveAgent(q.PrefixMesosMasterApiV1(), agentid)
+	if err != nil {
+		return err
+	}
+
+	before, err := reservationsBefore(q.PrefixMesosSlaveApiV0(agentid), wait)
+	if err != nil {
+		return err
+	}
 
 	var resources []mesos.Resource
 
@@ -52,13 +75,23 @@ func (q *UnreserveResources) DestroyVolume(agentid string, role string, principa
 		return err
 	}
 
-	return nil
+	return waitForChange(q.PrefixMesosSlaveApiV0(agentid), agentid, wait, before, nil, resources)
 }
 
-func (q *UnreserveResources) UnreserveResourceAll(agentid string, role string, principal string) error {
+func (q *UnreserveResources) UnreserveResourceAll(agentid string, role string, principal string, wait time.Duration) error {
+
+	agentid, err := resolveAgent(q.PrefixMesosMasterApiV1(), agentid)
+	if err != nil {
+		return err
+	}
 
 	client.PrintMessage("Unreserve all resources for %s", role)
 
+	before, err := reservationsBefore(q.PrefixMesosSlaveApiV0(agentid), wait)
+	if err != nil {
+		return err
+	}
+
 	resources, err := getResourcesOnRole(q.PrefixMesosSlaveApiV0(agentid), role, principal)
 	if err != nil {
 		return err
@@ -69,7 +102,7 @@ func (q *UnreserveResources) UnreserveResourceAll(agentid string, role string, p
 		if r.GetName() == "disk" && r.GetDisk().GetPersistence().GetID() != "" {
 			rid, fid := getIDsFromLabels(r.GetReservation().GetLabels().GetLabels())
 			client.PrintMessage("Destroying persistent volumes: %s", rid)
-			err = q.DestroyVolume(agentid, role, principal, r.GetScalar().GetValue(), rid, fid, r.GetDisk().GetPersistence().GetID(), r.GetDisk().GetVolume().GetContainerPath(), "")
+			err = q.DestroyVolume(agentid, role, principal, r.GetScalar().GetValue(), rid, fid, r.GetDisk().GetPersistence().GetID(), r.GetDisk().GetVolume().GetContainerPath(), "", 0)
 			if err != nil {
 				return err
 			}
@@ -85,18 +118,12 @@ func (q *UnreserveResources) UnreserveResourceAll(agentid string, role string, p
 		}
 	}
 
-	return nil
+	return waitForChange(q.PrefixMesosSlaveApiV0(agentid), agentid, wait, before, nil, resources)
 }
 
 func (q *UnreserveResources) UnreserveMesosResource(agentid string, resources ...mesos.Resource) error {
 
-	body := mastercalls.UnreserveResources(mesos.AgentID{Value: agentid}, resources...)
-	requestContent, err := json.Marshal(body)
-	if err != nil {
-		return err
-	}
-
-	_, err = client.HTTPServicePostJSON(q.PrefixMesosMasterApiV1(), requestContent)
+	err := unreserveResources(q.PrefixMesosMasterApiV1(), agentid, resources...)
 	if err != nil {
 		return err
 	} else {
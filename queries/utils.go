@@ -1,12 +1,17 @@
 package queries
 
 import (
+	"crypto/rand"
 	"encoding/json"
 	"errors"
+	"fmt"
 	mesos "github.com/mesos/mesos-go/api/v1/lib"
 	"github.com/mesos/mesos-go/api/v1/lib/master"
 	mastercalls "github.com/mesos/mesos-go/api/v1/lib/master/calls"
 	"github.com/minyk/dcos-resources/client"
+	"math"
+	"sort"
+	"strings"
 )
 
 func check(e error) {
@@ -17,7 +22,8 @@ func check(e error) {
 
 // Struct for Mesos API V0
 type AgentState struct {
-	AgentReservedResourcesFull ReservedResourcesFull `json:"reserved_resources_full,omitempty"`
+	AgentReservedResourcesFull   ReservedResourcesFull `json:"reserved_resources_full,omitempty"`
+	AgentUnreservedResourcesFull ResourceRole          `json:"unreserved_resources_full,omitempty"`
 }
 
 type ReservedResourcesFull map[string]ResourceRole
@@ -25,6 +31,21 @@ type ReservedResourcesFull map[string]ResourceRole
 type ResourceRole []mesos.Resource
 
 func getAgentList(masterUrl string) ([]string, error) {
+	agents, err := getAgents(masterUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []string
+
+	for _, agent := range agents {
+		list = append(list, agent.AgentInfo.ID.Value)
+	}
+
+	return list, nil
+}
+
+func getAgents(masterUrl string) ([]master.Response_GetAgents_Agent, error) {
 	body := mastercalls.GetAgents()
 
 	requestContent, err := json.Marshal(body)
@@ -43,22 +64,393 @@ func getAgentList(masterUrl string) ([]string, error) {
 		return nil, err
 	}
 
-	var list []string
+	return agents.GetAgents.GetAgents(), nil
+}
 
-	for _, agent := range agents.GetAgents.GetAgents() {
-		list = append(list, agent.AgentInfo.ID.Value)
+// agentIP returns the IP address of an agent from its PID, e.g. "slave(1)@10.0.0.1:5051".
+func agentIP(agent master.Response_GetAgents_Agent) string {
+	pid := agent.GetPID()
+	if i := strings.LastIndex(pid, "@"); i >= 0 {
+		pid = pid[i+1:]
 	}
+	if i := strings.LastIndex(pid, ":"); i >= 0 {
+		pid = pid[:i]
+	}
+	return pid
+}
 
-	return list, nil
+func reserveResources(masterUrl string, agentid string, resources ...mesos.Resource) error {
+	body := mastercalls.ReserveResources(mesos.AgentID{Value: agentid}, resources...)
+
+	requestContent, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.HTTPServicePostJSON(masterUrl, requestContent)
+	return err
+}
+
+func createVolumes(masterUrl string, agentid string, volumes ...mesos.Resource) error {
+	body := mastercalls.CreateVolumes(mesos.AgentID{Value: agentid}, volumes...)
+
+	requestContent, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.HTTPServicePostJSON(masterUrl, requestContent)
+	return err
+}
+
+func unreserveResources(masterUrl string, agentid string, resources ...mesos.Resource) error {
+	body := mastercalls.UnreserveResources(mesos.AgentID{Value: agentid}, resources...)
+
+	requestContent, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.HTTPServicePostJSON(masterUrl, requestContent)
+	return err
+}
+
+// withoutVolume returns the reservation underneath a persistent volume.
+func withoutVolume(r mesos.Resource) mesos.Resource {
+	r.AllocationInfo = nil
+	if r.Disk != nil {
+		disk := *r.Disk
+		disk.Persistence = nil
+		disk.Volume = nil
+		if disk.Source == nil {
+			r.Disk = nil
+		} else {
+			r.Disk = &disk
+		}
+	}
+	return r
+}
+
+// unreservedView strips reservations and volume information from a resource, so it can be compared with the
+// unreserved resources of an agent.
+func unreservedView(r mesos.Resource) mesos.Resource {
+	r = withoutVolume(r)
+	r.Role = nil
+	r.Reservation = nil
+	r.Reservations = nil
+	return r
+}
+
+// availableResources returns the unreserved resources of an agent which are not allocated to a framework.
+func availableResources(agent master.Response_GetAgents_Agent, state AgentState) ResourceRole {
+	var available ResourceRole
+	for _, r := range state.AgentUnreservedResourcesFull {
+		available = append(available, unreservedView(r))
+	}
+
+	for _, r := range agent.GetAllocatedResources() {
+		if r.ReservationRole() != "*" {
+			continue
+		}
+		allocated := unreservedView(r)
+		for i := range available {
+			if available[i].Contains(allocated) {
+				available[i].Subtract(allocated)
+				break
+			}
+		}
+	}
+
+	return available
+}
+
+// fitResources returns the part of the resources which the available resources can hold. Scalars are cut down
+// to what is available, disks to what is available from the same source, ranges and sets which are not available
+// are left out. It also describes what was cut.
+func fitResources(available ResourceRole, resources ...mesos.Resource) ([]mesos.Resource, []string) {
+	var remaining ResourceRole
+	for _, r := range available {
+		remaining = append(remaining, unreservedView(r))
+	}
+
+	var fitted []mesos.Resource
+	var cuts []string
+next:
+	for _, r := range resources {
+		need := unreservedView(r)
+		for i := range remaining {
+			if remaining[i].Contains(need) {
+				remaining[i].Subtract(need)
+				fitted = append(fitted, r)
+				continue next
+			}
+		}
+
+		if r.GetType() != mesos.SCALAR {
+			cuts = append(cuts, fmt.Sprintf("%s %s is not available", r.GetName(), resourceValue(r)))
+			continue
+		}
+
+		total := math.Floor(sourceTotal(remaining, need)*1000) / 1000
+		cuts = append(cuts, fmt.Sprintf("%s of %s %s is available", formatScalar(r.GetName(), total), formatScalar(r.GetName(), r.GetScalar().GetValue()), scalarName(need)))
+		if total <= 0 {
+			continue
+		}
+		for i := range remaining {
+			if remaining[i].GetName() == r.GetName() && remaining[i].GetType() == mesos.SCALAR && diskSource(remaining[i]) == diskSource(need) {
+				remaining[i].Scalar = &mesos.Value_Scalar{}
+			}
+		}
+		r.Scalar = &mesos.Value_Scalar{Value: total}
+		fitted = append(fitted, r)
+	}
+
+	return fitted, cuts
+}
+
+// missingResources returns the part of the resources which the existing reservations do not hold yet. Scalars are
+// cut down by the reserved amount, ranges and sets lose the reserved values. It also describes what is already reserved.
+func missingResources(existing ResourceRole, resources ...mesos.Resource) ([]mesos.Resource, []string) {
+	var remaining ResourceRole
+	for _, r := range existing {
+		remaining = append(remaining, unreservedView(r))
+	}
+
+	var missing []mesos.Resource
+	var reserved []string
+	for _, r := range resources {
+		need := unreservedView(r)
+		for i := range remaining {
+			if remaining[i].GetName() != need.GetName() || remaining[i].GetType() != need.GetType() || diskSource(remaining[i]) != diskSource(need) {
+				continue
+			}
+			switch need.GetType() {
+			case mesos.SCALAR:
+				have := math.Min(remaining[i].GetScalar().GetValue(), need.GetScalar().GetValue())
+				remaining[i].Scalar = &mesos.Value_Scalar{Value: remaining[i].GetScalar().GetValue() - have}
+				need.Scalar = &mesos.Value_Scalar{Value: roundScalar(need.GetScalar().GetValue() - have)}
+			case mesos.RANGES:
+				need.Ranges = need.GetRanges().Subtract(remaining[i].GetRanges())
+			case mesos.SET:
+				need.Set = need.GetSet().Subtract(remaining[i].GetSet())
+			}
+		}
+
+		switch {
+		case need.GetType() == mesos.SCALAR && need.GetScalar().GetValue() <= 0,
+			need.GetType() == mesos.RANGES && len(need.GetRanges().GetRange()) == 0,
+			need.GetType() == mesos.SET && len(need.GetSet().GetItem()) == 0:
+			reserved = append(reserved, fmt.Sprintf("%s %s", r.GetName(), resourceValue(r)))
+			continue
+		case need.GetType() == mesos.SCALAR && need.GetScalar().GetValue() < r.GetScalar().GetValue():
+			have := roundScalar(r.GetScalar().GetValue() - need.GetScalar().GetValue())
+			reserved = append(reserved, fmt.Sprintf("%s of %s %s", formatScalar(r.GetName(), have), formatScalar(r.GetName(), r.GetScalar().GetValue()), r.GetName()))
+		}
+
+		r.Scalar = need.Scalar
+		r.Ranges = need.Ranges
+		r.Set = need.Set
+		missing = append(missing, r)
+	}
+
+	return missing, reserved
+}
+
+// checkCapacity returns an error describing the shortfall if the unreserved resources of an agent cannot hold
+// the given resources.
+func checkCapacity(agentid string, unreserved ResourceRole, resources ...mesos.Resource) error {
+	var remaining ResourceRole
+	for _, r := range unreserved {
+		remaining = append(remaining, unreservedView(r))
+	}
+
+next:
+	for _, r := range resources {
+		need := unreservedView(r)
+		for i := range remaining {
+			if remaining[i].Contains(need) {
+				remaining[i].Subtract(need)
+				continue next
+			}
+		}
+
+		if need.GetType() == mesos.SCALAR {
+			return fmt.Errorf("need %s %s, %s unreserved on agent %s", formatScalar(need.GetName(), sourceTotal(resources, need)), scalarName(need), formatScalar(need.GetName(), sourceTotal(unreserved, need)), agentid)
+		}
+		return fmt.Errorf("need %s %s, not unreserved on agent %s", need.GetName(), resourceValue(need), agentid)
+	}
+
+	return nil
+}
+
+// scalarTotal sums the scalar resources with the given name.
+func scalarTotal(resources ResourceRole, name string) float64 {
+	total := 0.0
+	for _, r := range resources {
+		if r.GetName() == name && r.GetType() == mesos.SCALAR {
+			total += r.GetScalar().GetValue()
+		}
+	}
+	return total
+}
+
+// sourceTotal sums the scalar resources with the name and disk source of r, so a root disk is not compared
+// with MOUNT or PATH disks.
+func sourceTotal(resources ResourceRole, r mesos.Resource) float64 {
+	total := 0.0
+	for _, s := range resources {
+		if s.GetName() == r.GetName() && s.GetType() == mesos.SCALAR && diskSource(s) == diskSource(r) {
+			total += s.GetScalar().GetValue()
+		}
+	}
+	return total
+}
+
+// scalarName names a scalar resource together with the source of a disk, e.g. disk (MOUNT:/dcos/volume0).
+func scalarName(r mesos.Resource) string {
+	if source := diskSource(r); source != "" {
+		return r.GetName() + " (" + source + ")"
+	}
+	return r.GetName()
+}
+
+// formatScalars formats amounts of scalar resources, e.g. "cpus:1.000,mem:1024.000".
+func formatScalars(scalars map[string]float64) string {
+	var names []string
+	for name := range scalars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var values []string
+	for _, name := range names {
+		values = append(values, name+":"+formatScalar(name, scalars[name]))
+	}
+	return strings.Join(values, ",")
+}
+
+// isSubrole reports whether role is root or one of its descendants in the role hierarchy.
+func isSubrole(role string, root string) bool {
+	return role == root || strings.HasPrefix(role, root+"/")
+}
+
+// roleDepth returns the depth of a role in the role hierarchy, e.g. 0 for "eng" and 2 for "eng/backend/db".
+func roleDepth(role string) int {
+	return strings.Count(role, "/")
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	check(err)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// callMaster posts a call to the master operator API and decodes the response, if any.
+func callMaster(masterUrl string, body *master.Call) (master.Response, error) {
+	response := master.Response{}
+
+	requestContent, err := json.Marshal(body)
+	if err != nil {
+		return response, err
+	}
+
+	responseContent, err := client.HTTPServicePostJSON(masterUrl, requestContent)
+	if err != nil {
+		return response, err
+	}
+
+	if len(responseContent) > 0 {
+		err = json.Unmarshal(responseContent, &response)
+		if err != nil {
+			return response, err
+		}
+	}
+
+	return response, nil
+}
+
+// getAgentReservedTotals sums the reserved scalar resources of all agents per role and resource name from the
+// total resources GET_AGENTS reports, without asking each agent.
+func getAgentReservedTotals(masterUrl string) (map[string]map[string]float64, error) {
+	agents, err := getAgents(masterUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]map[string]float64)
+	for _, agent := range agents {
+		for _, r := range agent.GetTotalResources() {
+			if r.IsUnreserved() || r.GetType() != mesos.SCALAR {
+				continue
+			}
+			role := r.ReservationRole()
+			if totals[role] == nil {
+				totals[role] = make(map[string]float64)
+			}
+			totals[role][r.GetName()] += r.GetScalar().GetValue()
+		}
+	}
+
+	return totals, nil
+}
+
+// getReservedTotals sums the reserved scalar resources of all agents per role and resource name.
+func getReservedTotals(masterUrl string, slaveUrl func(string) string) (map[string]map[string]float64, error) {
+	agents, err := getAgentList(masterUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]map[string]float64)
+	for _, agentid := range agents {
+		resourcesFull, err := listResources(slaveUrl(agentid))
+		if err != nil {
+			return nil, err
+		}
+		for role, resources := range resourcesFull {
+			if totals[role] == nil {
+				totals[role] = make(map[string]float64)
+			}
+			for _, r := range resources {
+				if r.GetType() == mesos.SCALAR {
+					totals[role][r.GetName()] += r.GetScalar().GetValue()
+				}
+			}
+		}
+	}
+
+	return totals, nil
 }
 
 func getResourcesOnRole(urlPath string, role string, principal string) (ResourceRole, error) {
+	return getResourcesOnRoles(urlPath, role, principal, false)
+}
+
+// getResourcesOnRoles returns the resources reserved for a role, and for its children if includeChildren is set.
+func getResourcesOnRoles(urlPath string, role string, principal string, includeChildren bool) (ResourceRole, error) {
 	resourcesFull, err := listResources(urlPath)
 	if err != nil {
 		return nil, err
 	}
 
 	resources := resourcesFull[role]
+	if includeChildren {
+		resources = nil
+		var roles []string
+		for r := range resourcesFull {
+			if isSubrole(r, role) {
+				roles = append(roles, r)
+			}
+		}
+		sort.Strings(roles)
+		for _, r := range roles {
+			resources = append(resources, resourcesFull[r]...)
+		}
+	}
 	if len(resources) == 0 {
 		return nil, errors.New("no resources are reserved for role")
 	}
@@ -78,13 +470,7 @@ func getResourcesOnRole(urlPath string, role string, principal string) (Resource
 }
 
 func listResources(urlPath string) (ReservedResourcesFull, error) {
-	response, err := client.HTTPServiceGet(urlPath + "/state")
-	if err != nil {
-		return nil, err
-	}
-
-	agentStateReponse := AgentState{}
-	err = json.Unmarshal(response, &agentStateReponse)
+	agentStateReponse, err := getAgentState(urlPath)
 	if err != nil {
 		return nil, err
 	}
@@ -96,6 +482,55 @@ func listResources(urlPath string) (ReservedResourcesFull, error) {
 	return agentStateReponse.AgentReservedResourcesFull, nil
 }
 
+func getAgentState(urlPath string) (AgentState, error) {
+	agentState := AgentState{}
+
+	response, err := client.HTTPServiceGet(urlPath + "/state")
+	if err != nil {
+		return agentState, err
+	}
+
+	err = json.Unmarshal(response, &agentState)
+	if err != nil {
+		return agentState, err
+	}
+
+	return agentState, nil
+}
+
+// resourceKey identifies a reservation independently of its amount, so the same reservation can be
+// matched across two states of an agent. Reservations without a resource ID can share a key.
+func resourceKey(agentid string, r mesos.Resource) string {
+	rid, _ := getIDsFromLabels(r.GetReservation().GetLabels().GetLabels())
+	if rid != "" {
+		return strings.Join([]string{agentid, r.GetRole(), r.GetName(), rid}, "/")
+	}
+
+	var labels []string
+	for _, l := range r.GetReservation().GetLabels().GetLabels() {
+		labels = append(labels, l.Key+"="+l.GetValue())
+	}
+	sort.Strings(labels)
+
+	return strings.Join([]string{agentid, r.GetRole(), r.GetName(), r.GetType().String(), r.GetReservation().GetPrincipal(), diskSource(r), r.GetDisk().GetPersistence().GetID(), strings.Join(labels, ",")}, "/")
+}
+
+// resourceValue formats the value of scalar, ranges and set resources.
+func resourceValue(r mesos.Resource) string {
+	switch r.GetType() {
+	case mesos.RANGES:
+		var ranges []string
+		for _, rg := range r.GetRanges().GetRange() {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", rg.Begin, rg.End))
+		}
+		return "[" + strings.Join(ranges, ",") + "]"
+	case mesos.SET:
+		return "{" + strings.Join(r.GetSet().GetItem(), ",") + "}"
+	default:
+		return formatScalar(r.GetName(), r.GetScalar().GetValue())
+	}
+}
+
 func getIDsFromLabels(labels []mesos.Label) (string, string) {
 	var rid = ""
 	var fid = ""
@@ -109,3 +544,89 @@ func getIDsFromLabels(labels []mesos.Label) (string, string) {
 	}
 	return rid, fid
 }
+
+// resolveAgent returns the ID of the agent given by an exact ID, a hostname, an IP address or a unique ID prefix.
+func resolveAgent(masterUrl string, agent string) (string, error) {
+	agents, err := getAgents(masterUrl)
+	if err != nil {
+		return "", err
+	}
+
+	return matchAgent(agents, agent)
+}
+
+// getAgent returns the agent given by an exact ID, a hostname, an IP address or a unique ID prefix.
+func getAgent(masterUrl string, agent string) (master.Response_GetAgents_Agent, error) {
+	agents, err := getAgents(masterUrl)
+	if err != nil {
+		return master.Response_GetAgents_Agent{}, err
+	}
+
+	return findAgent(agents, agent)
+}
+
+// findAgent returns the agent among agents given by an exact ID, a hostname, an IP address or a unique ID prefix.
+func findAgent(agents []master.Response_GetAgents_Agent, agent string) (master.Response_GetAgents_Agent, error) {
+	agentid, err := matchAgent(agents, agent)
+	if err != nil {
+		return master.Response_GetAgents_Agent{}, err
+	}
+
+	for _, a := range agents {
+		if a.AgentInfo.ID.Value == agentid {
+			return a, nil
+		}
+	}
+	return master.Response_GetAgents_Agent{}, fmt.Errorf("no agent matches %s", agent)
+}
+
+// resolveAgents resolves several agents with a single GET_AGENTS call.
+func resolveAgents(masterUrl string, agents []string) ([]string, error) {
+	registered, err := getAgents(masterUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	var agentids []string
+	for _, agent := range agents {
+		agentid, err := matchAgent(registered, agent)
+		if err != nil {
+			return nil, err
+		}
+		agentids = append(agentids, agentid)
+	}
+
+	return agentids, nil
+}
+
+func matchAgent(agents []master.Response_GetAgents_Agent, agent string) (string, error) {
+	var byName, byPrefix []master.Response_GetAgents_Agent
+	for _, a := range agents {
+		switch {
+		case a.AgentInfo.ID.Value == agent:
+			return agent, nil
+		case a.AgentInfo.Hostname == agent || agentIP(a) == agent:
+			byName = append(byName, a)
+		case strings.HasPrefix(a.AgentInfo.ID.Value, agent):
+			byPrefix = append(byPrefix, a)
+		}
+	}
+
+	matches := byName
+	if len(matches) == 0 {
+		matches = byPrefix
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no agent matches %s", agent)
+	case 1:
+		return matches[0].AgentInfo.ID.Value, nil
+	default:
+		var candidates []string
+		for _, a := range matches {
+			candidates = append(candidates, a.AgentInfo.ID.Value+" ("+a.AgentInfo.Hostname+")")
+		}
+		return "", fmt.Errorf("%s matches %d agents: %s", agent, len(matches), strings.Join(candidates, ", "))
+	}
+}
@@ -0,0 +1,295 @@
+package queries
+
+import (
+	"encoding/json"
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testCluster serves the responses of the master to calls by their type, and the state of the agents in states.
+// Agents without a state are unreachable. It returns a function to stop serving.
+func testCluster(responses map[master.Call_Type]master.Response, states map[string]AgentState) func() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var call master.Call
+			if err := json.NewDecoder(r.Body).Decode(&call); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			response, ok := responses[call.GetType()]
+			if !ok {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+
+		agentid := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/agent/"), "/state")
+		state, ok := states[agentid]
+		if !ok {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(state)
+	}))
+
+	os.Setenv("DCOS_URL", server.URL)
+	os.Setenv("DCOS_ACS_TOKEN", "token")
+	os.Setenv("DCOS_SSL_VERIFY", "false")
+	return server.Close
+}
+
+// agentsResponse returns the response of the master to GET_AGENTS.
+func agentsResponse(agents ...master.Response_GetAgents_Agent) master.Response {
+	return master.Response{Type: master.Response_GET_AGENTS, GetAgents: &master.Response_GetAgents{Agents: agents}}
+}
+
+// testAgent returns a registered agent with a fault domain in us-east-1 and the given TEXT attributes.
+func testAgent(id string, hostname string, ip string, zone string, attributes map[string]string) master.Response_GetAgents_Agent {
+	pid := "slave(1)@" + ip + ":5051"
+	agent := master.Response_GetAgents_Agent{
+		AgentInfo: mesos.AgentInfo{
+			ID:       &mesos.AgentID{Value: id},
+			Hostname: hostname,
+		},
+		PID: &pid,
+	}
+	if zone != "" {
+		agent.AgentInfo.Domain = &mesos.DomainInfo{FaultDomain: &mesos.DomainInfo_FaultDomain{
+			Region: mesos.DomainInfo_FaultDomain_RegionInfo{Name: "us-east-1"},
+			Zone:   mesos.DomainInfo_FaultDomain_ZoneInfo{Name: zone},
+		}}
+	}
+	for name, value := range attributes {
+		agent.AgentInfo.Attributes = append(agent.AgentInfo.Attributes, mesos.Attribute{Name: name, Type: mesos.TEXT, Text: &mesos.Value_Text{Value: value}})
+	}
+	return agent
+}
+
+func TestMatchAgent(t *testing.T) {
+	agents := []master.Response_GetAgents_Agent{
+		testAgent("ef71-S0", "node-a", "10.0.0.1", "", nil),
+		testAgent("ef71-S1", "node-b", "10.0.0.2", "", nil),
+		testAgent("ab12-S2", "ef71", "10.0.0.3", "", nil),
+		testAgent("cd34-S3", "node-d", "10.0.0.4", "", nil),
+		testAgent("cd34-S4", "node-d", "10.0.0.5", "", nil),
+	}
+
+	tests := []struct {
+		agent   string
+		want    string
+		wantErr bool
+	}{
+		{"ef71-S0", "ef71-S0", false},
+		{"node-b", "ef71-S1", false},
+		{"10.0.0.3", "ab12-S2", false},
+		{"ab12", "ab12-S2", false},
+		{"ef71-S", "", true},
+		{"ef71", "ab12-S2", false},
+		{"node-d", "", true},
+		{"10.0.0.5", "cd34-S4", false},
+		{"node-x", "", true},
+		{"10.0.0", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := matchAgent(agents, tt.agent)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("matchAgent(%q) error = %v, wantErr %v", tt.agent, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("matchAgent(%q) = %q, want %q", tt.agent, got, tt.want)
+		}
+	}
+}
+
+func TestFindAgent(t *testing.T) {
+	agents := []master.Response_GetAgents_Agent{
+		testAgent("ef71-S0", "node-a", "10.0.0.1", "", nil),
+		testAgent("ef71-S1", "node-b", "10.0.0.2", "", nil),
+	}
+
+	tests := []struct {
+		agent   string
+		want    string
+		wantErr bool
+	}{
+		{"node-b", "ef71-S1", false},
+		{"10.0.0.1", "ef71-S0", false},
+		{"ef71", "", true},
+		{"node-x", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := findAgent(agents, tt.agent)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("findAgent(%q) error = %v, wantErr %v", tt.agent, err, tt.wantErr)
+			continue
+		}
+		if got.AgentInfo.GetID().GetValue() != tt.want {
+			t.Errorf("findAgent(%q) = %q, want %q", tt.agent, got.AgentInfo.GetID().GetValue(), tt.want)
+		}
+	}
+}
+
+// describe formats resources as name:value for comparison, with the source of disks.
+func describe(resources []mesos.Resource) []string {
+	var described []string
+	for _, r := range resources {
+		described = append(described, scalarName(r)+":"+resourceValue(r))
+	}
+	return described
+}
+
+func TestFitResources(t *testing.T) {
+	root := unreserved("disk", 1000)
+	mount := mountDisk(unreserved("disk", 500000), "/dcos/volume0")
+	ports := portRanges("ports", mesos.Value_Range{Begin: 31000, End: 32000})
+
+	tests := []struct {
+		name      string
+		available ResourceRole
+		resources []mesos.Resource
+		want      []string
+		wantCuts  int
+	}{
+		{"fits", ResourceRole{unreserved("cpus", 4), root},
+			[]mesos.Resource{resource("cpus", "r", "p", 2), resource("disk", "r", "p", 1000)},
+			[]string{"cpus:2.000", "disk:1000.000"}, 0},
+		{"cut down", ResourceRole{unreserved("cpus", 1.5)},
+			[]mesos.Resource{resource("cpus", "r", "p", 2)},
+			[]string{"cpus:1.500"}, 1},
+		{"cut across entries", ResourceRole{unreserved("cpus", 1), unreserved("cpus", 0.5)},
+			[]mesos.Resource{resource("cpus", "r", "p", 2)},
+			[]string{"cpus:1.500"}, 1},
+		{"second cut gets nothing", ResourceRole{unreserved("cpus", 1)},
+			[]mesos.Resource{resource("cpus", "r", "p", 2), resource("cpus", "r", "p", 1)},
+			[]string{"cpus:1.000"}, 2},
+		{"none available", ResourceRole{unreserved("mem", 1024)},
+			[]mesos.Resource{resource("cpus", "r", "p", 2)},
+			nil, 1},
+		{"root disk is not cut from mount disk", ResourceRole{root, mount},
+			[]mesos.Resource{resource("disk", "r", "p", 2000)},
+			[]string{"disk:1000.000"}, 1},
+		{"mount disk is not cut from root disk", ResourceRole{root, mount},
+			[]mesos.Resource{mountDisk(resource("disk", "r", "p", 600000), "/dcos/volume0")},
+			[]string{"disk (MOUNT:/dcos/volume0):500000.000"}, 1},
+		{"mount disk fits", ResourceRole{root, mount},
+			[]mesos.Resource{mountDisk(resource("disk", "r", "p", 500000), "/dcos/volume0"), resource("disk", "r", "p", 1000)},
+			[]string{"disk (MOUNT:/dcos/volume0):500000.000", "disk:1000.000"}, 0},
+		{"ranges", ResourceRole{ports},
+			[]mesos.Resource{portRanges("ports", mesos.Value_Range{Begin: 31000, End: 31010}), portRanges("ports", mesos.Value_Range{Begin: 80, End: 80})},
+			[]string{"ports:[31000-31010]"}, 1},
+	}
+
+	for _, tt := range tests {
+		fitted, cuts := fitResources(tt.available, tt.resources...)
+		if got := describe(fitted); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: fitResources() = %v, want %v", tt.name, got, tt.want)
+		}
+		if len(cuts) != tt.wantCuts {
+			t.Errorf("%s: fitResources() cuts = %v, want %d", tt.name, cuts, tt.wantCuts)
+		}
+	}
+}
+
+func TestCheckCapacity(t *testing.T) {
+	root := unreserved("disk", 1000)
+	mount := mountDisk(unreserved("disk", 500000), "/dcos/volume0")
+
+	tests := []struct {
+		name       string
+		unreserved ResourceRole
+		resources  []mesos.Resource
+		wantErr    string
+	}{
+		{"fits", ResourceRole{unreserved("cpus", 4), root},
+			[]mesos.Resource{resource("cpus", "r", "p", 4), resource("disk", "r", "p", 1000)}, ""},
+		{"cpus short", ResourceRole{unreserved("cpus", 1)},
+			[]mesos.Resource{resource("cpus", "r", "p", 1), resource("cpus", "r", "p", 0.5)},
+			"need 1.500 cpus, 1.000 unreserved on agent S0"},
+		{"root disk short beside mount disk", ResourceRole{root, mount},
+			[]mesos.Resource{resource("disk", "r", "p", 2000)},
+			"need 2000.000 disk, 1000.000 unreserved on agent S0"},
+		{"mount disk short", ResourceRole{root, mount},
+			[]mesos.Resource{mountDisk(resource("disk", "r", "p", 600000), "/dcos/volume0")},
+			"need 600000.000 disk (MOUNT:/dcos/volume0), 500000.000 unreserved on agent S0"},
+		{"mount disk fits", ResourceRole{root, mount},
+			[]mesos.Resource{mountDisk(resource("disk", "r", "p", 500000), "/dcos/volume0")}, ""},
+		{"ports not unreserved", ResourceRole{portRanges("ports", mesos.Value_Range{Begin: 31000, End: 32000})},
+			[]mesos.Resource{portRanges("ports", mesos.Value_Range{Begin: 80, End: 80})},
+			"need ports [80-80], not unreserved on agent S0"},
+	}
+
+	for _, tt := range tests {
+		err := checkCapacity("S0", tt.unreserved, tt.resources...)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.wantErr {
+			t.Errorf("%s: checkCapacity() error = %q, want %q", tt.name, got, tt.wantErr)
+		}
+	}
+}
+
+func TestMissingResources(t *testing.T) {
+	mount := mountDisk(resource("disk", "r", "p", 1000), "/dcos/volume0")
+
+	tests := []struct {
+		name         string
+		existing     ResourceRole
+		resources    []mesos.Resource
+		want         []string
+		wantReserved int
+	}{
+		{"nothing reserved", nil,
+			[]mesos.Resource{resource("cpus", "r", "p", 2)},
+			[]string{"cpus:2.000"}, 0},
+		{"all reserved", ResourceRole{resource("cpus", "r", "p", 2)},
+			[]mesos.Resource{resource("cpus", "r", "p", 2)},
+			nil, 1},
+		{"partly reserved", ResourceRole{resource("cpus", "r", "p", 0.5)},
+			[]mesos.Resource{resource("cpus", "r", "p", 2)},
+			[]string{"cpus:1.500"}, 1},
+		{"reserved across entries", ResourceRole{resource("cpus", "r", "p", 0.5), resource("cpus", "r", "p", 1)},
+			[]mesos.Resource{resource("cpus", "r", "p", 2)},
+			[]string{"cpus:0.500"}, 1},
+		{"reserved once only", ResourceRole{resource("cpus", "r", "p", 1)},
+			[]mesos.Resource{resource("cpus", "r", "p", 1), resource("cpus", "r", "p", 1)},
+			[]string{"cpus:1.000"}, 1},
+		{"other resource reserved", ResourceRole{resource("mem", "r", "p", 1024)},
+			[]mesos.Resource{resource("cpus", "r", "p", 2)},
+			[]string{"cpus:2.000"}, 0},
+		{"mount disk does not hold root disk", ResourceRole{mount},
+			[]mesos.Resource{resource("disk", "r", "p", 1000)},
+			[]string{"disk:1000.000"}, 0},
+		{"mount disk reserved", ResourceRole{mount},
+			[]mesos.Resource{mountDisk(resource("disk", "r", "p", 1000), "/dcos/volume0")},
+			nil, 1},
+		{"ranges", ResourceRole{portRanges("ports", mesos.Value_Range{Begin: 31000, End: 31005})},
+			[]mesos.Resource{portRanges("ports", mesos.Value_Range{Begin: 31000, End: 31010})},
+			[]string{"ports:[31006-31010]"}, 0},
+		{"sets", ResourceRole{{Name: "network", Type: mesos.SET.Enum(), Set: &mesos.Value_Set{Item: []string{"a", "b"}}}},
+			[]mesos.Resource{{Name: "network", Type: mesos.SET.Enum(), Set: &mesos.Value_Set{Item: []string{"a"}}}},
+			nil, 1},
+	}
+
+	for _, tt := range tests {
+		missing, reserved := missingResources(tt.existing, tt.resources...)
+		if got := describe(missing); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: missingResources() = %v, want %v", tt.name, got, tt.want)
+		}
+		if len(reserved) != tt.wantReserved {
+			t.Errorf("%s: missingResources() reserved = %v, want %d", tt.name, reserved, tt.wantReserved)
+		}
+	}
+}
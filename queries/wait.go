@@ -0,0 +1,100 @@
+package queries
+
+import (
+	"fmt"
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/minyk/dcos-resources/client"
+	"time"
+)
+
+const waitPollInterval = 2 * time.Second
+
+// reservationsBefore returns the reservations of an agent before a change, to compare with while waiting for it.
+// Nothing is fetched if timeout is zero.
+func reservationsBefore(slaveUrl string, timeout time.Duration) (ReservedResourcesFull, error) {
+	if timeout <= 0 {
+		return nil, nil
+	}
+	return listResources(slaveUrl)
+}
+
+// waitForChange polls the reservations of an agent until the added resources are reserved and the removed ones
+// are gone compared with before, or timeout has passed. Persistent volumes are matched by their IDs, reservations
+// by their amounts. It returns at once if timeout is zero.
+func waitForChange(slaveUrl string, agentid string, timeout time.Duration, before ReservedResourcesFull, added []mesos.Resource, removed []mesos.Resource) error {
+	if timeout <= 0 {
+		return nil
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		after, err := listResources(slaveUrl)
+		if err != nil {
+			return err
+		}
+		if changeVisible(before, after, added, removed) {
+			client.PrintMessage("Agent %s shows the change.", agentid)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for agent %s to show the change", timeout, agentid)
+		}
+
+		client.PrintVerbose("Waiting for agent %s to show the change...", agentid)
+		time.Sleep(waitPollInterval)
+	}
+}
+
+func changeVisible(before ReservedResourcesFull, after ReservedResourcesFull, added []mesos.Resource, removed []mesos.Resource) bool {
+	volumes := volumeIDs(after)
+	for _, r := range added {
+		if id := r.GetDisk().GetPersistence().GetID(); id != "" && !volumes[id] {
+			return false
+		}
+	}
+	for _, r := range removed {
+		if id := r.GetDisk().GetPersistence().GetID(); id != "" && volumes[id] {
+			return false
+		}
+	}
+
+	for role, resources := range byRole(added) {
+		expected := append(append(ResourceRole{}, before[role]...), resources...)
+		if missing, _ := missingResources(after[role], expected...); len(missing) > 0 {
+			return false
+		}
+	}
+	for role, resources := range byRole(removed) {
+		gone, _ := missingResources(after[role], before[role]...)
+		if missing, _ := missingResources(gone, resources...); len(missing) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// byRole groups resources which are not persistent volumes by the role they are reserved for. Creating or
+// destroying a volume leaves the amount of the reservation underneath it as it is.
+func byRole(resources []mesos.Resource) map[string]ResourceRole {
+	roles := make(map[string]ResourceRole)
+	for _, r := range resources {
+		if r.GetDisk().GetPersistence().GetID() != "" {
+			continue
+		}
+		roles[r.ReservationRole()] = append(roles[r.ReservationRole()], r)
+	}
+	return roles
+}
+
+func volumeIDs(resourcesFull ReservedResourcesFull) map[string]bool {
+	ids := make(map[string]bool)
+	for _, resources := range resourcesFull {
+		for _, r := range resources {
+			if id := r.GetDisk().GetPersistence().GetID(); id != "" {
+				ids[id] = true
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,48 @@
+package queries
+
+import (
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"testing"
+)
+
+func TestChangeVisible(t *testing.T) {
+	before := ReservedResourcesFull{
+		"r": {resource("cpus", "r", "p", 1), resource("disk", "r", "p", 100)},
+	}
+
+	tests := []struct {
+		name    string
+		after   ReservedResourcesFull
+		added   []mesos.Resource
+		removed []mesos.Resource
+		want    bool
+	}{
+		{"no change", before, nil, nil, true},
+		{"reservation not visible", before,
+			[]mesos.Resource{resource("cpus", "r", "p", 1)}, nil, false},
+		{"reservation visible", ReservedResourcesFull{"r": {resource("cpus", "r", "p", 2), resource("disk", "r", "p", 100)}},
+			[]mesos.Resource{resource("cpus", "r", "p", 1)}, nil, true},
+		{"reservation for another role", ReservedResourcesFull{"r": before["r"], "s": {resource("cpus", "s", "p", 1)}},
+			[]mesos.Resource{resource("cpus", "r", "p", 1)}, nil, false},
+		{"reservation for a new role", ReservedResourcesFull{"r": before["r"], "s": {resource("cpus", "s", "p", 1)}},
+			[]mesos.Resource{resource("cpus", "s", "p", 1)}, nil, true},
+		{"unreservation not visible", before,
+			nil, []mesos.Resource{resource("cpus", "r", "p", 1)}, false},
+		{"unreservation visible", ReservedResourcesFull{"r": {resource("disk", "r", "p", 100)}},
+			nil, []mesos.Resource{resource("cpus", "r", "p", 1)}, true},
+		{"volume not visible", before,
+			[]mesos.Resource{volume("r", 100, "v1")}, nil, false},
+		{"volume visible", ReservedResourcesFull{"r": {resource("cpus", "r", "p", 1), volume("r", 100, "v1")}},
+			[]mesos.Resource{volume("r", 100, "v1")}, nil, true},
+		{"volume still visible", ReservedResourcesFull{"r": {resource("cpus", "r", "p", 1), volume("r", 100, "v1")}},
+			nil, []mesos.Resource{volume("r", 100, "v1")}, false},
+		{"move visible", ReservedResourcesFull{"r": {resource("disk", "r", "p", 100)}, "s": {resource("cpus", "s", "p", 1)}},
+			[]mesos.Resource{resource("cpus", "s", "p", 1)}, []mesos.Resource{resource("cpus", "r", "p", 1)}, true},
+	}
+
+	for _, tt := range tests {
+		if got := changeVisible(before, tt.after, tt.added, tt.removed); got != tt.want {
+			t.Errorf("%s: changeVisible() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
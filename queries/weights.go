@@ -0,0 +1,68 @@
+package queries
+
+import (
+	"github.com/mesos/mesos-go/api/v1/lib"
+	mastercalls "github.com/mesos/mesos-go/api/v1/lib/master/calls"
+	"github.com/minyk/dcos-resources/client"
+	"sort"
+)
+
+type RoleWeights struct {
+	PrefixMesosMasterApiV1 func() string
+	PrefixMesosSlaveApiV0  func(string) string
+	PrefixMesosSlaveApiV1  func(string) string
+}
+
+func NewRoleWeights() *RoleWeights {
+	return &RoleWeights{
+		PrefixMesosMasterApiV1: func() string { return "/mesos/api/v1/" },
+		PrefixMesosSlaveApiV0:  func(agentid string) string { return "/agent/" + agentid },
+		PrefixMesosSlaveApiV1:  func(agentid string) string { return "/agent/" + agentid + "/api/v1" },
+	}
+}
+
+func (q *RoleWeights) ListWeights() error {
+	weights, err := getWeights(q.PrefixMesosMasterApiV1())
+	if err != nil {
+		return err
+	}
+
+	var roles []string
+	for role := range weights {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+
+	client.PrintMessage("Role\t\tWeight")
+	for _, role := range roles {
+		client.PrintMessage("%s\t\t%f", role, weights[role])
+	}
+
+	return nil
+}
+
+func (q *RoleWeights) SetWeight(role string, weight float64) error {
+	_, err := callMaster(q.PrefixMesosMasterApiV1(), mastercalls.UpdateWeights(mesos.WeightInfo{Role: &role, Weight: weight}))
+	if err != nil {
+		return err
+	}
+
+	client.PrintMessage("Setting weight is successful.")
+
+	return nil
+}
+
+// getWeights returns the weights of roles which are not the default weight of 1.
+func getWeights(masterUrl string) (map[string]float64, error) {
+	response, err := callMaster(masterUrl, mastercalls.GetWeights())
+	if err != nil {
+		return nil, err
+	}
+
+	weights := make(map[string]float64)
+	for _, info := range response.GetGetWeights().GetWeightInfos() {
+		weights[info.GetRole()] = info.GetWeight()
+	}
+
+	return weights, nil
+}